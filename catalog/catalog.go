@@ -0,0 +1,431 @@
+// Package catalog reads declarative error catalogs and generates code from them.
+//
+// A catalog lists error definitions with their code, status codes, message template,
+// user reason, retry classification, severity and documentation link. It can be written
+// in YAML or JSON:
+//
+//	package: payments
+//	domain: payments.example.com
+//	errors:
+//	  - code: PAYMENT.DECLINED
+//	    http: 402
+//	    grpc: FAILED_PRECONDITION
+//	    message: "payment {payment_id} declined: {decline_reason}"
+//	    reason: Your card was declined.
+//	    params:
+//	      payment_id: string
+//	    retry: not_retryable
+//	    docs_url: https://docs.example.com/errors/payment-declined
+//
+// The xerrgen command turns a catalog into Go definitions, see GenerateGo.
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nduyhai/xerr"
+	"google.golang.org/grpc/codes"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog is a declarative list of error definitions.
+type Catalog struct {
+	Package string  `yaml:"package,omitempty" json:"package,omitempty"` // Default Go package of generated code
+	Domain  string  `yaml:"domain,omitempty" json:"domain,omitempty"`   // Default domain of the errors
+	Errors  []Entry `yaml:"errors" json:"errors"`                       // Error definitions
+}
+
+// Entry describes a single error definition of a catalog.
+// Only Code is required; the status codes and message default to those of the code
+// in the xerr code catalog.
+type Entry struct {
+	Code        string            `yaml:"code" json:"code"`                                   // Error code, such as PAYMENT.DECLINED
+	Name        string            `yaml:"name,omitempty" json:"name,omitempty"`               // Go name, derived from the code by default
+	HTTP        int               `yaml:"http,omitempty" json:"http,omitempty"`               // HTTP status code
+	GRPC        string            `yaml:"grpc,omitempty" json:"grpc,omitempty"`               // gRPC status code, such as NOT_FOUND or NotFound
+	Domain      string            `yaml:"domain,omitempty" json:"domain,omitempty"`           // Domain, overriding the catalog domain
+	Message     string            `yaml:"message,omitempty" json:"message,omitempty"`         // Developer-facing message template
+	Reason      string            `yaml:"reason,omitempty" json:"reason,omitempty"`           // User-facing reason template
+	Params      map[string]string `yaml:"params,omitempty" json:"params,omitempty"`           // Types of the template parameters
+	Retry       string            `yaml:"retry,omitempty" json:"retry,omitempty"`             // retryable, not_retryable or retry_after_delay
+	RetryDelay  string            `yaml:"retry_delay,omitempty" json:"retry_delay,omitempty"` // Delay before retrying, such as 30s
+	Severity    string            `yaml:"severity,omitempty" json:"severity,omitempty"`       // debug, info, warning, error or critical
+	DocsURL     string            `yaml:"docs_url,omitempty" json:"docs_url,omitempty"`       // Link to the documentation of the error
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"` // When the error is returned
+}
+
+// Retry classifications accepted in Entry.Retry.
+const (
+	RetryRetryable    = "retryable"
+	RetryNotRetryable = "not_retryable"
+	RetryAfterDelay   = "retry_after_delay"
+)
+
+// defaultParamType is the type of template parameters missing from Entry.Params.
+const defaultParamType = "string"
+
+// paramTypes maps the parameter types accepted in Entry.Params to Go types.
+var paramTypes = map[string]string{
+	"string":   "string",
+	"int":      "int",
+	"int64":    "int64",
+	"float":    "float64",
+	"float64":  "float64",
+	"bool":     "bool",
+	"time":     "time.Time",
+	"duration": "time.Duration",
+	"any":      "any",
+}
+
+var (
+	codePattern  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*(\.[A-Z][A-Z0-9_]*)*$`)
+	paramPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	namePattern  = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+)
+
+// Load reads a catalog from a YAML or JSON file and validates it.
+// Files with a .json extension are decoded as JSON, any other file as YAML.
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data, strings.EqualFold(filepath.Ext(path), ".json"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse decodes a catalog from YAML, or from JSON if isJSON is true.
+// Unknown fields are rejected. The catalog is not validated.
+func Parse(data []byte, isJSON bool) (*Catalog, error) {
+	var c Catalog
+	if isJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c); err != nil {
+			return nil, err
+		}
+		return &c, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks the catalog and returns every problem found, joined with errors.Join.
+// It rejects missing, malformed and duplicate codes, clashing Go names, invalid HTTP
+// and gRPC status codes, unknown retry classifications, severities and parameter types,
+// template parameters that map to the same Go parameter name, and parameters that are
+// declared but unused.
+func (c *Catalog) Validate() error {
+	var errs []error
+	if c.Package != "" && !token.IsIdentifier(c.Package) {
+		errs = append(errs, fmt.Errorf("package %q is not a valid Go package name", c.Package))
+	}
+	if len(c.Errors) == 0 {
+		errs = append(errs, errors.New("catalog has no errors"))
+	}
+
+	seenCodes := make(map[string]int)
+	seenNames := make(map[string]int)
+	for i, e := range c.Errors {
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("errors[%d] (%s): %s", i, e.Code, fmt.Sprintf(format, args...)))
+		}
+
+		switch first, dup := seenCodes[e.Code]; {
+		case e.Code == "":
+			fail("code is required")
+		case !codePattern.MatchString(e.Code):
+			fail("code must be upper-case segments of letters, digits and underscores separated by %q", xerr.CodeSeparator)
+		case dup:
+			fail("duplicate code, first defined in errors[%d]", first)
+		default:
+			seenCodes[e.Code] = i
+		}
+
+		if e.Name != "" && !namePattern.MatchString(e.Name) {
+			fail("name %q must be an exported Go identifier without underscores", e.Name)
+		} else if name := e.GoName(); name != "" {
+			if first, dup := seenNames[name]; dup {
+				fail("Go name %s clashes with errors[%d]", name, first)
+			} else {
+				seenNames[name] = i
+			}
+		}
+
+		if e.HTTP != 0 && (e.HTTP < 400 || e.HTTP > 599) {
+			fail("http status %d is not an error status (400-599)", e.HTTP)
+		}
+		if _, err := e.GRPCCode(); err != nil {
+			fail("%v", err)
+		}
+		if _, _, err := e.Retryability(); err != nil {
+			fail("%v", err)
+		}
+		if _, err := e.SeverityLevel(); err != nil {
+			fail("%v", err)
+		}
+		if e.DocsURL != "" {
+			if u, err := url.Parse(e.DocsURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				fail("docs_url %q is not an absolute http(s) URL", e.DocsURL)
+			}
+		}
+
+		used := make(map[string]bool)
+		// Wrap<Name> takes err before the parameters, so no parameter may be named err
+		goParams := map[string]string{"err": "err"}
+		for _, p := range e.placeholders() {
+			used[p] = true
+			if !paramPattern.MatchString(p) {
+				fail("template parameter {%s} is not a valid name", p)
+				continue
+			}
+			name := paramName(p)
+			if other, dup := goParams[name]; dup {
+				fail("template parameter {%s} clashes with %s: both become Go parameter %s", p, other, name)
+			} else {
+				goParams[name] = "{" + p + "}"
+			}
+		}
+		for _, p := range sortedKeys(e.Params) {
+			if !used[p] {
+				fail("parameter %s is not used in the message or reason", p)
+			}
+			if _, ok := paramTypes[e.Params[p]]; !ok {
+				fail("parameter %s has unknown type %q", p, e.Params[p])
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GoName returns the Go name of the entry: Name if set, or else the code in camel case,
+// such as PaymentDeclined for PAYMENT.DECLINED.
+func (e Entry) GoName() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return exportedName(e.Code)
+}
+
+// GRPCCode returns the gRPC status code of the entry.
+// It accepts both the canonical name, such as NOT_FOUND, and the Go name, such as NotFound.
+// It returns codes.OK if the entry has no gRPC code.
+func (e Entry) GRPCCode() (codes.Code, error) {
+	if e.GRPC == "" {
+		return codes.OK, nil
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(e.GRPC))); err == nil && code != codes.OK {
+		return code, nil
+	}
+	for c := codes.Code(1); c <= codes.Unauthenticated; c++ {
+		if c.String() == e.GRPC {
+			return c, nil
+		}
+	}
+	return codes.OK, fmt.Errorf("grpc code %q is not a known error code", e.GRPC)
+}
+
+// Retryability returns the retry classification and delay of the entry.
+// It returns xerr.RetryUnspecified if the entry has none.
+func (e Entry) Retryability() (xerr.Retryability, time.Duration, error) {
+	var delay time.Duration
+	if e.RetryDelay != "" {
+		d, err := time.ParseDuration(e.RetryDelay)
+		if err != nil || d <= 0 {
+			return xerr.RetryUnspecified, 0, fmt.Errorf("retry_delay %q is not a positive duration", e.RetryDelay)
+		}
+		delay = d
+	}
+
+	switch e.Retry {
+	case "":
+		if delay > 0 {
+			return xerr.RetryableAfterDelay, delay, nil
+		}
+		return xerr.RetryUnspecified, 0, nil
+	case RetryAfterDelay:
+		return xerr.RetryableAfterDelay, delay, nil
+	case RetryRetryable, RetryNotRetryable:
+		if delay > 0 {
+			return xerr.RetryUnspecified, 0, fmt.Errorf("retry_delay requires retry to be %s", RetryAfterDelay)
+		}
+		if e.Retry == RetryRetryable {
+			return xerr.Retryable, 0, nil
+		}
+		return xerr.NotRetryable, 0, nil
+	default:
+		return xerr.RetryUnspecified, 0, fmt.Errorf("retry %q must be one of %s, %s or %s",
+			e.Retry, RetryRetryable, RetryNotRetryable, RetryAfterDelay)
+	}
+}
+
+// SeverityLevel returns the severity of the entry.
+// It returns xerr.SeverityUnspecified if the entry has none.
+func (e Entry) SeverityLevel() (xerr.Severity, error) {
+	if e.Severity == "" {
+		return xerr.SeverityUnspecified, nil
+	}
+	severity, ok := xerr.ParseSeverity(e.Severity)
+	if !ok {
+		return xerr.SeverityUnspecified, fmt.Errorf("severity %q must be one of debug, info, warning, error or critical", e.Severity)
+	}
+	return severity, nil
+}
+
+// Definitions returns the definitions of the catalog entries, without registering them.
+// Unlike generated code, they are built at run time, for example to document the catalog.
+func (c *Catalog) Definitions() ([]*xerr.Definition, error) {
+	defs := make([]*xerr.Definition, 0, len(c.Errors))
+	var errs []error
+	for i, e := range c.Errors {
+		opts, err := e.options(c.Domain)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("errors[%d] (%s): %w", i, e.Code, err))
+			continue
+		}
+		applied := make([]xerr.Option, len(opts))
+		for j, opt := range opts {
+			applied[j] = opt.apply
+		}
+		defs = append(defs, xerr.NewDefinition(xerr.Code(e.Code), applied...))
+	}
+	return defs, errors.Join(errs...)
+}
+
+// option is an xerr.Option of a catalog entry, with the Go expression generated code uses for it.
+type option struct {
+	apply xerr.Option
+	expr  string
+}
+
+// options returns the options of the entry, with domain as the default domain.
+// Definitions applies them and generated code spells them out, so both build the same errors.
+func (e Entry) options(domain string) ([]option, error) {
+	grpcCode, err := e.GRPCCode()
+	if err != nil {
+		return nil, err
+	}
+	retry, delay, err := e.Retryability()
+	if err != nil {
+		return nil, err
+	}
+	severity, err := e.SeverityLevel()
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []option
+	if e.HTTP != 0 {
+		opts = append(opts, option{xerr.WithHTTPStatus(e.HTTP), fmt.Sprintf("xerr.WithHTTPStatus(%d)", e.HTTP)})
+	}
+	if grpcCode != codes.OK {
+		opts = append(opts, option{xerr.WithGRPCStatus(grpcCode), "xerr.WithGRPCStatus(codes." + grpcCode.String() + ")"})
+	}
+	if e.Domain != "" {
+		domain = e.Domain
+	}
+	if domain != "" {
+		opts = append(opts, option{xerr.WithDomain(domain), fmt.Sprintf("xerr.WithDomain(%q)", domain)})
+	}
+	if e.Message != "" {
+		opts = append(opts, option{xerr.WithMessage(e.Message), fmt.Sprintf("xerr.WithMessage(%q)", e.Message)})
+	}
+	if e.Reason != "" {
+		opts = append(opts, option{xerr.WithUserReason(e.Reason), fmt.Sprintf("xerr.WithUserReason(%q)", e.Reason)})
+	}
+	if delay > 0 {
+		opts = append(opts, option{xerr.WithRetryDelay(delay), "xerr.WithRetryDelay(" + durationExpr(delay) + ")"})
+	} else if retry != xerr.RetryUnspecified {
+		opts = append(opts, option{xerr.WithRetryability(retry), "xerr.WithRetryability(xerr." + retryIdent(retry) + ")"})
+	}
+	if severity != xerr.SeverityUnspecified {
+		opts = append(opts, option{xerr.WithSeverity(severity), "xerr.WithSeverity(xerr." + severityIdent(severity) + ")"})
+	}
+	if e.DocsURL != "" {
+		opts = append(opts, option{xerr.WithDocsURL(e.DocsURL), fmt.Sprintf("xerr.WithDocsURL(%q)", e.DocsURL)})
+	}
+	return opts, nil
+}
+
+// FromDefinitions returns a catalog of the given definitions, such as those of
+// xerr.Definitions(), so that registered errors can be documented like catalog files.
+// The entries have the effective status codes, retry classification and severity
+// of the definitions.
+func FromDefinitions(defs []*xerr.Definition) *Catalog {
+	c := &Catalog{Errors: make([]Entry, 0, len(defs))}
+	for _, def := range defs {
+		e := Entry{
+			Code:    string(def.Code()),
+			HTTP:    def.HTTPCode(),
+			Domain:  def.Domain(),
+			Message: def.Message(),
+			Reason:  def.UserReason(),
+			DocsURL: def.DocsURL(),
+		}
+		if severity := def.Severity(); severity != xerr.SeverityUnspecified {
+			e.Severity = severity.String()
+		}
+		if code := def.GRPCCode(); code != codes.OK {
+			e.GRPC = code.String()
+		}
+		switch def.Retryability() {
+		case xerr.Retryable:
+			e.Retry = RetryRetryable
+		case xerr.NotRetryable:
+			e.Retry = RetryNotRetryable
+		case xerr.RetryableAfterDelay:
+			e.Retry = RetryAfterDelay
+			if delay := def.RetryDelay(); delay > 0 {
+				e.RetryDelay = delay.String()
+			}
+		}
+		c.Errors = append(c.Errors, e)
+	}
+	return c
+}
+
+// placeholders returns the names of the template parameters of the message and reason,
+// in order of appearance.
+func (e Entry) placeholders() []string {
+	names := xerr.TemplateParams(e.Message)
+	for _, name := range xerr.TemplateParams(e.Reason) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,129 @@
+package catalog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nduyhai/xerr"
+	"google.golang.org/grpc/codes"
+)
+
+func TestLoadYAMLAndJSON(t *testing.T) {
+	fromYAML, err := Load("testdata/accounts.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := Load("testdata/accounts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Fatalf("expected YAML and JSON catalogs to match:\n%+v\n%+v", fromYAML, fromJSON)
+	}
+
+	locked := fromYAML.Errors[1]
+	if code, _ := locked.GRPCCode(); code != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %s", code)
+	}
+	if retry, delay, _ := locked.Retryability(); retry != xerr.RetryableAfterDelay || delay != 15*time.Minute {
+		t.Fatalf("expected retry after 15m, got %s after %s", retry, delay)
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	if _, err := Parse([]byte("errors:\n  - code: A\n    htpp: 404\n"), false); err == nil {
+		t.Fatal("expected unknown YAML field to be rejected")
+	}
+	if _, err := Parse([]byte(`{"errors": [{"code": "A", "htpp": 404}]}`), true); err == nil {
+		t.Fatal("expected unknown JSON field to be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+		want  string
+	}{
+		{"missing code", Entry{}, "code is required"},
+		{"malformed code", Entry{Code: "payment-failed"}, "code must be upper-case"},
+		{"http status", Entry{Code: "A", HTTP: 200}, "http status 200 is not an error status"},
+		{"grpc code", Entry{Code: "A", GRPC: "NOPE"}, `grpc code "NOPE" is not a known error code`},
+		{"grpc OK", Entry{Code: "A", GRPC: "OK"}, `grpc code "OK"`},
+		{"retry", Entry{Code: "A", Retry: "sometimes"}, `retry "sometimes" must be one of`},
+		{"retry delay", Entry{Code: "A", RetryDelay: "soon"}, `retry_delay "soon" is not a positive duration`},
+		{"retry delay without delay class", Entry{Code: "A", Retry: "retryable", RetryDelay: "1s"}, "retry_delay requires retry"},
+		{"severity", Entry{Code: "A", Severity: "fatal"}, `severity "fatal"`},
+		{"docs url", Entry{Code: "A", DocsURL: "/errors/a"}, "is not an absolute http(s) URL"},
+		{"name", Entry{Code: "A", Name: "my_error"}, `name "my_error" must be an exported Go identifier`},
+		{"param type", Entry{Code: "A", Message: "{id}", Params: map[string]string{"id": "uuid"}}, `parameter id has unknown type "uuid"`},
+		{"unused param", Entry{Code: "A", Params: map[string]string{"id": "string"}}, "parameter id is not used"},
+		{"placeholder", Entry{Code: "A", Message: "user {user id}"}, "template parameter {user id} is not a valid name"},
+		{"param name clash", Entry{Code: "A", Message: "{user_id} {user__id}"}, "template parameter {user__id} clashes with {user_id}: both become Go parameter userID"},
+		{"param name clash with keyword suffix", Entry{Code: "A", Message: "{type} {type_param}"}, "template parameter {type_param} clashes with {type}: both become Go parameter typeParam"},
+		{"param name clash with err", Entry{Code: "A", Message: "{err} {err_param}"}, "template parameter {err_param} clashes with {err}: both become Go parameter errParam"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Catalog{Errors: []Entry{tt.entry}}
+			err := c.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateCatalog(t *testing.T) {
+	c := &Catalog{
+		Package: "my-errors",
+		Errors: []Entry{
+			{Code: "USER.NOT_FOUND"},
+			{Code: "USER.NOT_FOUND"},
+			{Code: "USER_NOT.FOUND"},
+		},
+	}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		`package "my-errors" is not a valid Go package name`,
+		"errors[1] (USER.NOT_FOUND): duplicate code, first defined in errors[0]",
+		"errors[2] (USER_NOT.FOUND): Go name UserNotFound clashes with errors[0]",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+
+	if err := (&Catalog{}).Validate(); err == nil || !strings.Contains(err.Error(), "catalog has no errors") {
+		t.Fatalf("expected an empty catalog to be rejected, got %v", err)
+	}
+}
+
+func TestNames(t *testing.T) {
+	for code, want := range map[string]string{
+		"NOT_FOUND":             "NotFound",
+		"PAYMENT.CARD_DECLINED": "PaymentCardDeclined",
+		"USER.ID_INVALID":       "UserIDInvalid",
+		"HTTP_URL_TOO_LONG":     "HTTPURLTooLong",
+	} {
+		if got := exportedName(code); got != want {
+			t.Errorf("exportedName(%q) = %q, want %q", code, got, want)
+		}
+	}
+	for param, want := range map[string]string{
+		"user_id": "userID",
+		"amount":  "amount",
+		"type":    "typeParam",
+		"err":     "errParam",
+		"2fa":     "p2fa",
+	} {
+		if got := paramName(param); got != want {
+			t.Errorf("paramName(%q) = %q, want %q", param, got, want)
+		}
+	}
+}
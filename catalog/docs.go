@@ -0,0 +1,238 @@
+package catalog
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	htmltemplate "html/template"
+	"net/http"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode"
+
+	"github.com/nduyhai/xerr"
+	"google.golang.org/grpc/codes"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// markdownTemplate renders a docPage as Markdown.
+var markdownTemplate = texttemplate.Must(texttemplate.New("docs.md.tmpl").
+	Funcs(texttemplate.FuncMap{"cell": markdownCell}).
+	ParseFS(templates, "templates/docs.md.tmpl"))
+
+// htmlTemplate renders a docPage as HTML.
+var htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/docs.html.tmpl"))
+
+// DefaultDocsTitle is the title of generated documentation when DocsOptions.Title is empty.
+const DefaultDocsTitle = "Error Reference"
+
+// DocsOptions configures GenerateMarkdown and GenerateHTML.
+type DocsOptions struct {
+	Title string // Title of the page; defaults to DefaultDocsTitle
+}
+
+// GenerateMarkdown generates a Markdown reference page for the errors of the catalog.
+//
+// The page starts with a summary table and documents, for every error, its HTTP status,
+// gRPC code, domain, severity, retry classification, message template, user reason,
+// metadata keys and an example HTTP response. Values not set in the catalog are those
+// the errors get at run time, such as the status codes of known codes.
+//
+// To document the errors registered at run time, use FromDefinitions:
+//
+//	doc, err := catalog.GenerateMarkdown(catalog.FromDefinitions(xerr.Definitions()), catalog.DocsOptions{})
+func GenerateMarkdown(c *Catalog, opts DocsOptions) ([]byte, error) {
+	page, err := newDocPage(c, opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := markdownTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateHTML generates a standalone HTML reference page for the errors of the catalog,
+// with the same content as GenerateMarkdown.
+func GenerateHTML(c *Catalog, opts DocsOptions) ([]byte, error) {
+	page, err := newDocPage(c, opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// docPage is the data of the documentation templates.
+type docPage struct {
+	Title  string
+	Errors []docError
+}
+
+// docError is the documentation of a single error.
+type docError struct {
+	Code        string
+	Anchor      string
+	Description string
+	HTTP        int
+	HTTPText    string
+	GRPC        string
+	Domain      string
+	Severity    string
+	Retry       string
+	Message     string
+	Reason      string
+	DocsURL     string
+	Params      []docParam
+	Example     string
+}
+
+// docParam is a template parameter, exported as a metadata key.
+type docParam struct {
+	Name string
+	Type string
+}
+
+// newDocPage builds the template data of a catalog.
+func newDocPage(c *Catalog, opts DocsOptions) (*docPage, error) {
+	defs, err := c.Definitions()
+	if err != nil {
+		return nil, err
+	}
+	page := &docPage{Title: opts.Title}
+	if page.Title == "" {
+		page.Title = DefaultDocsTitle
+	}
+	for i, def := range defs {
+		e := c.Errors[i]
+		doc := docError{
+			Code:        string(def.Code()),
+			Anchor:      anchor(string(def.Code())),
+			Description: strings.TrimSpace(e.Description),
+			HTTP:        def.HTTPCode(),
+			HTTPText:    http.StatusText(def.HTTPCode()),
+			GRPC:        grpcName(def.GRPCCode()),
+			Domain:      def.Domain(),
+			Severity:    def.Severity().String(),
+			Retry:       retryText(def.Retryability(), def.RetryDelay()),
+			Message:     def.Message(),
+			Reason:      def.UserReason(),
+			DocsURL:     def.DocsURL(),
+		}
+		params := make(xerr.Params)
+		for _, p := range e.placeholders() {
+			typ := e.Params[p]
+			if typ == "" {
+				typ = defaultParamType
+			}
+			doc.Params = append(doc.Params, docParam{Name: p, Type: typ})
+			params[p] = exampleValue(typ)
+		}
+		if doc.Example, err = example(def, params); err != nil {
+			return nil, err
+		}
+		page.Errors = append(page.Errors, doc)
+	}
+	return page, nil
+}
+
+// example returns the indented HTTP response body of an error created from def with params.
+// The occurrence ID and timestamp, which differ for every error, are left out.
+func example(def *xerr.Definition, params xerr.Params) (string, error) {
+	args := []any{params}
+	if len(params) == 0 {
+		args = nil
+	}
+	body, _ := def.New(args...).(*xerr.StructuredError).ToHTTPJSON()
+
+	var httpErr xerr.HTTPError
+	if err := json.Unmarshal(body, &httpErr); err != nil {
+		return "", err
+	}
+	httpErr.ID, httpErr.Timestamp = "", time.Time{}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(httpErr); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// exampleValue returns an example value of a template parameter of the given type.
+func exampleValue(typ string) any {
+	switch typ {
+	case "int", "int64":
+		return 42
+	case "float", "float64":
+		return 1.5
+	case "bool":
+		return true
+	case "time":
+		return time.Date(2026, time.January, 2, 15, 4, 5, 0, time.UTC)
+	case "duration":
+		return 30 * time.Second
+	default:
+		return "example"
+	}
+}
+
+// retryText describes a retry classification.
+func retryText(retry xerr.Retryability, delay time.Duration) string {
+	switch {
+	case retry == xerr.Retryable:
+		return "yes"
+	case retry == xerr.RetryableAfterDelay && delay > 0:
+		return "after " + delay.String()
+	case retry == xerr.RetryableAfterDelay:
+		return "after a delay"
+	default:
+		return "no"
+	}
+}
+
+// grpcName returns the canonical name of a gRPC code, such as NOT_FOUND.
+func grpcName(code codes.Code) string {
+	name := code.String()
+	if code == codes.OK || !strings.ContainsFunc(name, unicode.IsLower) {
+		return strings.ToUpper(name)
+	}
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// anchor returns the anchor of a heading as generated by GitHub, such as paymentdeclined
+// for PAYMENT.DECLINED, so that links work in rendered Markdown and in HTML.
+func anchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_', r == '-':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// markdownCell escapes s for use in a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,76 @@
+package catalog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nduyhai/xerr"
+	"google.golang.org/grpc/codes"
+)
+
+func TestGenerateMarkdown(t *testing.T) {
+	c, err := Load("testdata/accounts.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := GenerateMarkdown(c, DocsOptions{Title: "Accounts Errors"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "testdata/accounts.md.golden", doc)
+}
+
+func TestGenerateHTML(t *testing.T) {
+	c, err := Load("testdata/accounts.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := GenerateHTML(c, DocsOptions{Title: "Accounts Errors"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "testdata/accounts.html.golden", doc)
+}
+
+func TestGenerateMarkdownFromDefinitions(t *testing.T) {
+	registry := xerr.NewRegistry()
+	for _, def := range []*xerr.Definition{
+		xerr.NewDefinition("ORDER.NOT_FOUND",
+			xerr.WithHTTPStatus(http.StatusNotFound),
+			xerr.WithGRPCStatus(codes.NotFound),
+			xerr.WithDomain("orders.example.com"),
+			xerr.WithMessage("order {order_id} not found"),
+			xerr.WithUserReason("We could not find this order."),
+		),
+		xerr.NewDefinition("ORDER.PAYMENT_PENDING",
+			xerr.WithGRPCStatus(codes.Unavailable),
+			xerr.WithMessage("payment of order %s is pending"),
+			xerr.WithRetryDelay(5*time.Second),
+		),
+		xerr.NewDefinition(xerr.INVALID_ARGUMENT),
+	} {
+		if err := registry.Register(def); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	doc, err := GenerateMarkdown(FromDefinitions(registry.Definitions()), DocsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "testdata/registry.md.golden", doc)
+}
+
+func TestGrpcName(t *testing.T) {
+	for code, want := range map[codes.Code]string{
+		codes.OK:               "OK",
+		codes.NotFound:         "NOT_FOUND",
+		codes.DeadlineExceeded: "DEADLINE_EXCEEDED",
+		codes.Internal:         "INTERNAL",
+	} {
+		if got := grpcName(code); got != want {
+			t.Errorf("grpcName(%v) = %q, want %q", code, got, want)
+		}
+	}
+}
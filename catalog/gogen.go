@@ -0,0 +1,206 @@
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nduyhai/xerr"
+)
+
+// GoOptions configures GenerateGo.
+type GoOptions struct {
+	Package   string // Go package of the generated file; defaults to the catalog package
+	Source    string // Name of the catalog file, mentioned in the generated header
+	Generator string // Name of the generator, mentioned in the generated header; defaults to xerrgen
+}
+
+// GenerateGo validates the catalog and generates a Go file with, for every entry:
+//
+//   - a Code constant, such as CodePaymentDeclined
+//   - a Definition registered with xerr.Define, such as ErrPaymentDeclined
+//   - type-safe constructors taking the template parameters, such as NewPaymentDeclined
+//     and WrapPaymentDeclined
+//   - a matcher, such as IsPaymentDeclined
+//
+// Template parameters are typed as declared in Entry.Params, and as string otherwise.
+func GenerateGo(c *Catalog, opts GoOptions) ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = c.Package
+	}
+	if pkg == "" {
+		return nil, errors.New("no Go package: set it in the catalog or the options")
+	}
+
+	generator := opts.Generator
+	if generator == "" {
+		generator = "xerrgen"
+	}
+
+	g := &goGenerator{catalog: c}
+	g.printf("// Code generated by %s", generator)
+	if opts.Source != "" {
+		g.printf(" from %s", opts.Source)
+	}
+	g.printf(". DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	g.imports()
+
+	g.printf("// Error codes.\nconst (\n")
+	for _, e := range c.Errors {
+		g.printf("// Code%s is the code of Err%[1]s.\n", e.GoName())
+		g.printf("Code%s xerr.Code = %q\n", e.GoName(), e.Code)
+	}
+	g.printf(")\n")
+
+	for _, e := range c.Errors {
+		g.entry(e)
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// goGenerator accumulates the source of a generated Go file.
+type goGenerator struct {
+	catalog *Catalog
+	buf     bytes.Buffer
+}
+
+func (g *goGenerator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// imports writes the import block with the packages used by the generated code.
+func (g *goGenerator) imports() {
+	usesTime, usesCodes := false, false
+	for _, e := range g.catalog.Errors {
+		if _, delay, _ := e.Retryability(); delay > 0 {
+			usesTime = true
+		}
+		for _, p := range e.placeholders() {
+			usesTime = usesTime || strings.HasPrefix(e.paramType(p), "time.")
+		}
+		if code, _ := e.GRPCCode(); code != 0 {
+			usesCodes = true
+		}
+	}
+
+	g.printf("import (\n\"errors\"\n")
+	if usesTime {
+		g.printf("\"time\"\n")
+	}
+	g.printf("\n\"github.com/nduyhai/xerr\"\n")
+	if usesCodes {
+		g.printf("\"google.golang.org/grpc/codes\"\n")
+	}
+	g.printf(")\n\n")
+}
+
+// entry writes the definition, constructors and matcher of a catalog entry.
+func (g *goGenerator) entry(e Entry) {
+	name := e.GoName()
+	params := e.placeholders()
+
+	g.printf("\n// Err%s is the definition of the %s error.\n", name, e.Code)
+	if e.Description != "" {
+		g.printf("//\n")
+		for _, line := range strings.Split(strings.TrimSpace(e.Description), "\n") {
+			g.printf("// %s\n", strings.TrimSpace(line))
+		}
+	}
+	if e.DocsURL != "" {
+		g.printf("//\n// Documentation: %s\n", e.DocsURL)
+	}
+	g.printf("var Err%s = xerr.Define(Code%[1]s,\n", name)
+	// Entries are validated before generation, so parse errors are ignored
+	opts, _ := e.options(g.catalog.Domain)
+	for _, opt := range opts {
+		g.printf("%s,\n", opt.expr)
+	}
+	g.printf(")\n")
+
+	var decls, args []string
+	for _, p := range params {
+		decls = append(decls, paramName(p)+" "+e.paramType(p))
+		args = append(args, fmt.Sprintf("%q: %s", p, paramName(p)))
+	}
+	callArgs := ""
+	if len(params) > 0 {
+		callArgs = "xerr.Params{" + strings.Join(args, ", ") + "}"
+	}
+
+	g.printf("\n// New%s creates a %s error.\n", name, e.Code)
+	g.printf("func New%[1]s(%[2]s) xerr.Error {\nreturn Err%[1]s.New(%[3]s)\n}\n", name, strings.Join(decls, ", "), callArgs)
+
+	g.printf("\n// Wrap%s creates a %s error caused by err.\n// It returns nil if err is nil.\n", name, e.Code)
+	g.printf("func Wrap%[1]s(%[2]s) xerr.Error {\nreturn Err%[1]s.Wrap(%[3]s)\n}\n",
+		name, strings.Join(append([]string{"err error"}, decls...), ", "), strings.Join(append([]string{"err"}, nonEmpty(callArgs)...), ", "))
+
+	g.printf("\n// Is%s reports whether err or any error in its chain is a %s error.\n", name, e.Code)
+	g.printf("func Is%s(err error) bool {\nreturn errors.Is(err, Err%[1]s)\n}\n", name)
+}
+
+// paramType returns the Go type of a template parameter.
+func (e Entry) paramType(name string) string {
+	if t, ok := e.Params[name]; ok {
+		return paramTypes[t]
+	}
+	return paramTypes[defaultParamType]
+}
+
+// severityIdent returns the name of the xerr constant for a severity.
+func severityIdent(s xerr.Severity) string {
+	name := s.String()
+	return "Severity" + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// retryIdent returns the name of the xerr constant for a retry classification.
+func retryIdent(r xerr.Retryability) string {
+	switch r {
+	case xerr.NotRetryable:
+		return "NotRetryable"
+	case xerr.Retryable:
+		return "Retryable"
+	case xerr.RetryableAfterDelay:
+		return "RetryableAfterDelay"
+	default:
+		return "RetryUnspecified"
+	}
+}
+
+// durationExpr returns a readable Go expression for d, such as 30 * time.Second.
+func durationExpr(d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	} {
+		if d%unit.d == 0 {
+			return strconv.FormatInt(int64(d/unit.d), 10) + " * " + unit.name
+		}
+	}
+	return "time.Duration(" + strconv.FormatInt(int64(d), 10) + ")"
+}
+
+// nonEmpty returns s as a single-element slice, or nil if it is empty.
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
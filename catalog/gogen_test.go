@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// golden compares got with the golden file, or rewrites the file with -update.
+func golden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("output differs from %s (run go test -update to refresh):\n%s", path, got)
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	c, err := Load("testdata/accounts.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := GenerateGo(c, GoOptions{Source: "accounts.yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "testdata/accounts.go.golden", src)
+}
+
+func TestGenerateGoPackage(t *testing.T) {
+	c := &Catalog{Errors: []Entry{{Code: "A"}}}
+	if _, err := GenerateGo(c, GoOptions{}); err == nil {
+		t.Fatal("expected an error without a package")
+	}
+	src, err := GenerateGo(c, GoOptions{Package: "errs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "package errs\n") || strings.Contains(string(src), "grpc/codes") {
+		t.Fatalf("unexpected output:\n%s", src)
+	}
+
+	invalid := &Catalog{Package: "errs", Errors: []Entry{{Code: "A", HTTP: 200}}}
+	if _, err := GenerateGo(invalid, GoOptions{}); err == nil {
+		t.Fatal("expected an invalid catalog to be rejected")
+	}
+}
@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// initialisms are the words kept upper case in Go names, following Go naming conventions.
+var initialisms = map[string]bool{
+	"API": true, "DB": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "JWT": true, "OTP": true, "SQL": true, "TCP": true, "TLS": true,
+	"TTL": true, "UID": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// words splits s into words on any character that is not a letter or digit.
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// exportedName converts a code such as PAYMENT.CARD_ID_INVALID to PaymentCardIDInvalid.
+func exportedName(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		b.WriteString(camelWord(w))
+	}
+	return b.String()
+}
+
+// paramName converts a template parameter such as user_id to a Go parameter name such as userID.
+// Names that clash with Go keywords, predeclared identifiers used by the generated code
+// or the err parameter get a Param suffix.
+func paramName(s string) string {
+	var b strings.Builder
+	for i, w := range words(s) {
+		if i == 0 {
+			b.WriteString(strings.ToLower(w))
+			continue
+		}
+		b.WriteString(camelWord(w))
+	}
+	name := b.String()
+	switch {
+	case name == "":
+		return "param"
+	case token.IsKeyword(name), name == "err", name == "errors", name == "xerr", name == "time":
+		return name + "Param"
+	case unicode.IsDigit(rune(name[0])):
+		return "p" + name
+	}
+	return name
+}
+
+// camelWord returns w with only its first letter upper case, or all upper case for initialisms.
+func camelWord(w string) string {
+	upper := strings.ToUpper(w)
+	if initialisms[upper] {
+		return upper
+	}
+	return upper[:1] + strings.ToLower(w[1:])
+}
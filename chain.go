@@ -0,0 +1,119 @@
+package xerr
+
+import (
+	"errors"
+	"iter"
+)
+
+// Chain returns an iterator over err and every error in its chain, outermost first.
+// It follows Unwrap() error to the cause and visits every aggregated error of a MultiError
+// and every branch of Unwrap() []error, such as those of errors.Join, depth first and in order:
+//
+//	for layer := range xerr.Chain(err) {
+//		fmt.Println(layer)
+//	}
+func Chain(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		walkChain(err, yield)
+	}
+}
+
+// walkChain yields err and its chain, and reports whether the iteration should continue.
+func walkChain(err error, yield func(error) bool) bool {
+	for err != nil {
+		if !yield(err) {
+			return false
+		}
+		if errs, ok := branches(err); ok {
+			for _, branch := range errs {
+				if !walkChain(branch, yield) {
+					return false
+				}
+			}
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return true
+}
+
+// Root returns the innermost error of the chain of err, such as the error returned by
+// a driver and wrapped several times. For errors with several branches, it follows the
+// first one. It returns nil if err is nil.
+func Root(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if errs, ok := branches(err); ok {
+			next = nil
+			if len(errs) > 0 {
+				next = errs[0]
+			}
+		}
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// First returns the outermost Error in the chain of err.
+func First(err error) (Error, bool) {
+	for layer := range Chain(err) {
+		if e, ok := layer.(Error); ok {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// Last returns the innermost Error in the chain of err, in the order of Chain.
+func Last(err error) (Error, bool) {
+	var last Error
+	for layer := range Chain(err) {
+		if e, ok := layer.(Error); ok {
+			last = e
+		}
+	}
+	return last, last != nil
+}
+
+// Codes returns the codes of the Errors in the chain of err, in the order of Chain
+// and without duplicates.
+func Codes(err error) []Code {
+	var codes []Code
+	seen := make(map[Code]bool)
+	for layer := range Chain(err) {
+		if e, ok := layer.(Error); ok {
+			code := Code(e.GetCode())
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	return codes
+}
+
+// HasCode reports whether an Error in the chain of err has exactly the given code.
+// Use errors.Is with a Code target to also match its descendants in the code hierarchy.
+func HasCode(err error, code Code) bool {
+	for layer := range Chain(err) {
+		if e, ok := layer.(Error); ok && Code(e.GetCode()) == code {
+			return true
+		}
+	}
+	return false
+}
+
+// branches returns the errors aggregated by err, such as the errors of a MultiError or of
+// errors.Join. The second return value reports whether err aggregates errors.
+func branches(err error) ([]error, bool) {
+	switch x := err.(type) {
+	case *MultiError:
+		return x.branches(), true
+	case interface{ Unwrap() []error }:
+		return x.Unwrap(), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,96 @@
+package xerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	inner := Wrap(sql.ErrNoRows, NOT_FOUND)
+	other := errors.New("cache miss")
+	joined := errors.Join(inner, other)
+	outer := fmt.Errorf("load user: %w", New("USER_LOOKUP_FAILED", "lookup failed", WithCause(joined)))
+
+	got := slices.Collect(Chain(outer))
+	want := []error{outer, errors.Unwrap(outer), joined, inner, sql.ErrNoRows, other}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d layers, got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("layer %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	// Iteration stops early, including inside branches
+	var visited int
+	for layer := range Chain(outer) {
+		visited++
+		if layer == inner {
+			break
+		}
+	}
+	if visited != 4 {
+		t.Fatalf("expected to stop after 4 layers, visited %d", visited)
+	}
+
+	if slices.Collect(Chain(nil)) != nil {
+		t.Fatal("expected an empty chain for nil")
+	}
+}
+
+func TestRootFirstLast(t *testing.T) {
+	inner := Wrap(sql.ErrNoRows, NOT_FOUND)
+	outer := Wrapf(fmt.Errorf("repository: %w", inner), INTERNAL, "load user")
+
+	if Root(outer) != sql.ErrNoRows {
+		t.Fatalf("expected the root cause, got %v", Root(outer))
+	}
+	if Root(sql.ErrNoRows) != sql.ErrNoRows || Root(nil) != nil {
+		t.Fatal("expected an error without cause to be its own root")
+	}
+	if Root(Join(New("A", "a"), sql.ErrConnDone)).(Error).GetCode() != "A" {
+		t.Fatal("expected Root to follow the first branch")
+	}
+
+	if first, ok := First(outer); !ok || first != outer {
+		t.Fatalf("expected the outermost Error, got %v", first)
+	}
+	if last, ok := Last(outer); !ok || last != inner {
+		t.Fatalf("expected the innermost Error, got %v", last)
+	}
+	if _, ok := First(sql.ErrNoRows); ok {
+		t.Fatal("expected no Error in a plain error chain")
+	}
+	if _, ok := Last(nil); ok {
+		t.Fatal("expected no Error in a nil chain")
+	}
+}
+
+func TestCodesAndHasCode(t *testing.T) {
+	err := Join(
+		Wrap(Wrap(sql.ErrNoRows, NOT_FOUND), INTERNAL),
+		New("AUTH.USER.LOCKED", "locked"),
+		New(string(NOT_FOUND), "again"),
+	)
+	want := []Code{INTERNAL, NOT_FOUND, "AUTH.USER.LOCKED"}
+	if got := Codes(err); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if Codes(sql.ErrNoRows) != nil {
+		t.Fatal("expected no codes in a plain error chain")
+	}
+
+	if !HasCode(err, "AUTH.USER.LOCKED") || !HasCode(err, INTERNAL) {
+		t.Fatal("expected HasCode to find codes in every layer")
+	}
+	if HasCode(err, "AUTH.USER") || HasCode(err, UNAVAILABLE) {
+		t.Fatal("expected HasCode to match codes exactly")
+	}
+	if !errors.Is(err, Code("AUTH.USER")) {
+		t.Fatal("expected errors.Is to match ancestor codes")
+	}
+}
@@ -0,0 +1,164 @@
+package xerr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Matcher reports whether an error matches a classification Rule.
+type Matcher func(err error) bool
+
+// MatchError returns a Matcher for errors that match target with errors.Is,
+// such as sql.ErrNoRows.
+func MatchError(target error) Matcher {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// MatchType returns a Matcher for errors whose chain contains an error of type T,
+// as found by errors.As. If match is not nil, it must also report true for that error:
+//
+//	xerr.MatchType(func(err net.Error) bool { return err.Timeout() })
+func MatchType[T error](match func(T) bool) Matcher {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target) && (match == nil || match(target))
+	}
+}
+
+// Rule classifies the errors it matches with a code from the code catalog.
+type Rule struct {
+	Match    Matcher // Reports whether the rule applies to an error
+	Code     Code    // Code of the errors the rule matches
+	Priority int     // Rules with a higher priority are tried first
+}
+
+// StandardPriority is the priority of the rules returned by StandardRules.
+// Rules registered with the default priority of zero are tried before them.
+const StandardPriority = -100
+
+// StandardRules returns the built-in rules for the errors of the standard library:
+// cancellations, deadlines and network timeouts, missing files and rows, permission
+// errors, and closed or broken connections.
+func StandardRules() []Rule {
+	return []Rule{
+		{MatchError(context.Canceled), CANCELLED, StandardPriority},
+		{MatchError(context.DeadlineExceeded), TIMEOUT, StandardPriority},
+		{MatchError(os.ErrDeadlineExceeded), TIMEOUT, StandardPriority},
+		{MatchType(func(err net.Error) bool { return err.Timeout() }), TIMEOUT, StandardPriority},
+		{MatchError(os.ErrNotExist), NOT_FOUND, StandardPriority},
+		{MatchError(sql.ErrNoRows), NOT_FOUND, StandardPriority},
+		{MatchError(os.ErrExist), ALREADY_EXISTS, StandardPriority},
+		{MatchError(os.ErrPermission), PERMISSION_DENIED, StandardPriority},
+		{MatchError(sql.ErrConnDone), UNAVAILABLE, StandardPriority},
+		{MatchError(net.ErrClosed), UNAVAILABLE, StandardPriority},
+		{MatchError(io.ErrUnexpectedEOF), UNAVAILABLE, StandardPriority},
+		{MatchType[*net.OpError](nil), UNAVAILABLE, StandardPriority},
+		{MatchType[*net.DNSError](nil), UNAVAILABLE, StandardPriority},
+	}
+}
+
+// Classifier infers the code of errors that don't carry one, such as the errors returned
+// by the standard library and third-party packages, from an ordered list of rules.
+// Wrap and Wrapf consult the DefaultClassifier when wrapping with the UNKNOWN or an empty
+// code, and WrapDefault always does. A Classifier is safe for concurrent use.
+type Classifier struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// NewClassifier creates a Classifier with the given rules.
+// To extend the built-in rules, pass StandardRules.
+func NewClassifier(rules ...Rule) *Classifier {
+	c := &Classifier{}
+	for _, rule := range rules {
+		if err := c.Register(rule); err != nil {
+			panic(err)
+		}
+	}
+	return c
+}
+
+// DefaultClassifier is the process-wide classifier, made of the StandardRules.
+var DefaultClassifier = NewClassifier(StandardRules()...)
+
+// Register adds a rule to the classifier. Rules are tried by decreasing priority,
+// and rules with the same priority in the order they were registered.
+// It returns an error if the rule has no matcher or no code.
+func (c *Classifier) Register(rule Rule) error {
+	if rule.Match == nil {
+		return errors.New("xerr: cannot register a rule without a matcher")
+	}
+	if rule.Code == "" {
+		return errors.New("xerr: cannot register a rule with an empty code")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, _ := slices.BinarySearchFunc(c.rules, rule.Priority, func(r Rule, priority int) int {
+		if r.Priority >= priority {
+			return -1
+		}
+		return 1
+	})
+	c.rules = slices.Insert(c.rules, i, rule)
+	return nil
+}
+
+// Classify returns the code of the first rule that matches err.
+// The second return value reports whether a rule matched.
+func (c *Classifier) Classify(err error) (Code, bool) {
+	if err == nil {
+		return "", false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, rule := range c.rules {
+		if rule.Match(err) {
+			return rule.Code, true
+		}
+	}
+	return "", false
+}
+
+// Rules returns the rules of the classifier, in the order they are tried.
+func (c *Classifier) Rules() []Rule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clone(c.rules)
+}
+
+// resolve returns the code to wrap err with: code itself, or the classification of err
+// when code is UNKNOWN or empty. Errors whose chain already contains an Error keep the
+// given code, since the classification was made by whoever created that Error.
+func (c *Classifier) resolve(err error, code Code) Code {
+	if code != UNKNOWN && code != "" {
+		return code
+	}
+	if _, ok := First(err); ok {
+		return code
+	}
+	if classified, ok := c.Classify(err); ok {
+		return classified
+	}
+	return code
+}
+
+// RegisterRule adds a rule to the DefaultClassifier.
+// It returns an error if the rule has no matcher or no code.
+func RegisterRule(rule Rule) error {
+	return DefaultClassifier.Register(rule)
+}
+
+// Classify returns the code the DefaultClassifier infers for err.
+// The second return value reports whether a rule matched.
+func Classify(err error) (Code, bool) {
+	return DefaultClassifier.Classify(err)
+}
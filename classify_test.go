@@ -0,0 +1,144 @@
+package xerr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestWrapDefaultClassifiesStandardErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Code
+	}{
+		{context.Canceled, CANCELLED},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), TIMEOUT},
+		{&net.DNSError{Err: "i/o timeout", IsTimeout: true}, TIMEOUT},
+		{&net.DNSError{Err: "no such host", IsNotFound: true}, UNAVAILABLE},
+		{&fs.PathError{Op: "open", Path: "/missing", Err: syscall.ENOENT}, NOT_FOUND},
+		{fmt.Errorf("load user: %w", sql.ErrNoRows), NOT_FOUND},
+		{os.ErrPermission, PERMISSION_DENIED},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, UNAVAILABLE},
+		{io.ErrUnexpectedEOF, UNAVAILABLE},
+		{errors.New("something else"), UNKNOWN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			err := WrapDefault(tt.err)
+			if err.GetCode() != string(tt.want) {
+				t.Fatalf("expected %s, got %s", tt.want, err.GetCode())
+			}
+			if err.GetHTTPCode() != tt.want.HTTPCode() || err.GetMessage() != tt.err.Error() {
+				t.Fatalf("expected the defaults of %s and the original message, got %d %q", tt.want, err.GetHTTPCode(), err.GetMessage())
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatal("expected the original error to stay reachable")
+			}
+		})
+	}
+}
+
+func TestWrapClassifiesUnknownCodes(t *testing.T) {
+	if got := Wrap(sql.ErrNoRows, UNKNOWN).GetCode(); got != string(NOT_FOUND) {
+		t.Fatalf("expected Wrap with UNKNOWN to classify, got %s", got)
+	}
+	if got := Wrapf(context.Canceled, "", "stopped").GetCode(); got != string(CANCELLED) {
+		t.Fatalf("expected Wrapf with an empty code to classify, got %s", got)
+	}
+	if got := Wrap(sql.ErrNoRows, INTERNAL).GetCode(); got != string(INTERNAL) {
+		t.Fatalf("expected an explicit code to be kept, got %s", got)
+	}
+
+	// Errors already classified by an Error in their chain are not reclassified
+	inner := fmt.Errorf("repository: %w", Wrap(sql.ErrNoRows, INTERNAL))
+	if got := Wrap(inner, UNKNOWN).GetCode(); got != string(UNKNOWN) {
+		t.Fatalf("expected no classification below an Error, got %s", got)
+	}
+	if got := WrapDefault(inner); got.GetCode() != string(INTERNAL) || got.GetHTTPCode() != http.StatusInternalServerError {
+		t.Fatalf("expected the code and status of the inner Error, got %s %d", got.GetCode(), got.GetHTTPCode())
+	}
+	if got := WrapDefault(inner); !errors.Is(got, sql.ErrNoRows) || got.GetCause() != inner {
+		t.Fatalf("expected err to be wrapped, got %v", got)
+	}
+	notFound := NewStandardError(NOT_FOUND, "missing")
+	if got := WrapDefault(notFound); got != notFound {
+		t.Fatalf("expected an Error to be returned unchanged, got %v", got)
+	}
+}
+
+type quotaError struct{ remaining int }
+
+func (e *quotaError) Error() string { return fmt.Sprintf("%d requests remaining", e.remaining) }
+
+func TestClassifierRules(t *testing.T) {
+	errLocked := errors.New("account locked")
+	c := NewClassifier(StandardRules()...)
+	for _, rule := range []Rule{
+		{Match: MatchType(func(err *quotaError) bool { return err.remaining == 0 }), Code: RESOURCE_EXHAUSTED},
+		{Match: MatchError(errLocked), Code: PERMISSION_DENIED, Priority: -200},
+		{Match: MatchError(context.Canceled), Code: ABORTED},
+		{Match: MatchError(errLocked), Code: "AUTH.USER.LOCKED", Priority: 10},
+	} {
+		if err := c.Register(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		err  error
+		want Code
+	}{
+		{&quotaError{}, RESOURCE_EXHAUSTED},
+		{errLocked, "AUTH.USER.LOCKED"},
+		{context.Canceled, ABORTED},
+		{sql.ErrNoRows, NOT_FOUND},
+	}
+	for _, tt := range tests {
+		if got, ok := c.Classify(tt.err); !ok || got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.err, tt.want, got)
+		}
+	}
+	if _, ok := c.Classify(&quotaError{remaining: 1}); ok {
+		t.Error("expected the predicate of MatchType to be applied")
+	}
+	if _, ok := c.Classify(nil); ok {
+		t.Error("expected nil not to be classified")
+	}
+
+	rules := c.Rules()
+	if rules[0].Code != "AUTH.USER.LOCKED" || rules[len(rules)-1].Code != PERMISSION_DENIED {
+		t.Fatalf("expected rules sorted by priority, got %v first and %v last", rules[0].Code, rules[len(rules)-1].Code)
+	}
+	if len(DefaultClassifier.Rules()) != len(StandardRules()) {
+		t.Fatal("expected the rules of a classifier not to leak into the DefaultClassifier")
+	}
+
+	if c.Register(Rule{Code: INTERNAL}) == nil {
+		t.Fatal("expected an error for a rule without a matcher")
+	}
+	if c.Register(Rule{Match: MatchError(io.EOF)}) == nil {
+		t.Fatal("expected an error for a rule without a code")
+	}
+}
+
+func TestFactoryClassifier(t *testing.T) {
+	c := NewClassifier(Rule{Match: MatchError(sql.ErrNoRows), Code: "USERS.NOT_FOUND"})
+	f := newTestFactory(WithClassifier(c))
+	if got := f.Wrap(sql.ErrNoRows, UNKNOWN).GetCode(); got != "USERS.NOT_FOUND" {
+		t.Fatalf("expected the factory classifier to be used, got %s", got)
+	}
+	if got := f.Wrapf(context.Canceled, "", "stopped").GetCode(); got != "" {
+		t.Fatalf("expected only the rules of the factory classifier, got %s", got)
+	}
+	if newTestFactory().Classifier() != DefaultClassifier {
+		t.Fatal("expected DefaultClassifier by default")
+	}
+}
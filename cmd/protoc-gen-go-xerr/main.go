@@ -0,0 +1,38 @@
+// Command protoc-gen-go-xerr is a protoc plugin that generates xerr error definitions
+// from protobuf enums annotated with the options of xerr/options.proto.
+//
+// Every value of an error enum becomes an error whose code, and thus the reason of its
+// ErrorInfo, is the value name, and whose domain is the (xerr.domain) file option.
+// As with xerrgen, the generated file declares for every error a code constant,
+// a definition registered with xerr.Define, type-safe New and Wrap constructors
+// and an Is matcher.
+//
+// Usage:
+//
+//	protoc -I . -I path/to/xerr/proto --go_out=. --go-xerr_out=. errors.proto
+//
+// See proto/xerr/options.proto for the available options.
+package main
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func main() {
+	protogen.Options{}.Run(generate)
+}
+
+// generate generates the error definitions of the files to generate.
+func generate(gen *protogen.Plugin) error {
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		if err := generateFile(gen, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	xerrpb "github.com/nduyhai/xerr/proto/xerr"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// loadFile reads a FileDescriptorProto in text format from testdata.
+func loadFile(t *testing.T, name string) *descriptorpb.FileDescriptorProto {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd := &descriptorpb.FileDescriptorProto{}
+	if err := prototext.Unmarshal(data, fd); err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+// run runs the plugin on a file, as protoc would.
+func run(t *testing.T, fd *descriptorpb.FileDescriptorProto) *pluginpb.CodeGeneratorResponse {
+	t.Helper()
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{fd.GetName()},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(xerrpb.File_xerr_options_proto),
+			fd,
+		},
+	}
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := generate(gen); err != nil {
+		gen.Error(err)
+	}
+	return gen.Response()
+}
+
+func TestGenerate(t *testing.T) {
+	resp := run(t, loadFile(t, "users.textproto"))
+	if resp.Error != nil {
+		t.Fatal(resp.GetError())
+	}
+	if len(resp.File) != 1 || resp.File[0].GetName() != "example.com/users/errors_xerr.pb.go" {
+		t.Fatalf("expected a single errors_xerr.pb.go file, got %v", resp.File)
+	}
+
+	const golden = "testdata/users_xerr.pb.go.golden"
+	got := resp.File[0].GetContent()
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Fatalf("output differs from %s (run go test -update to refresh):\n%s", golden, got)
+	}
+}
+
+func TestGenerateSkipsFilesWithoutErrors(t *testing.T) {
+	fd := loadFile(t, "users.textproto")
+	fd.EnumType = fd.EnumType[1:]
+	fd.MessageType = nil
+	fd.SourceCodeInfo = nil
+	if resp := run(t, fd); resp.Error != nil || len(resp.File) != 0 {
+		t.Fatalf("expected no output, got %v", resp)
+	}
+}
+
+func TestGenerateReportsInvalidErrors(t *testing.T) {
+	fd := loadFile(t, "users.textproto")
+	opts := fd.EnumType[0].Value[1].Options
+	proto.SetExtension(opts, xerrpb.E_Error, &xerrpb.ErrorOptions{Grpc: "NOT_A_CODE"})
+
+	resp := run(t, fd)
+	if !strings.Contains(resp.GetError(), `users/errors.proto: errors[0] (USER_NOT_FOUND): grpc code "NOT_A_CODE"`) {
+		t.Fatalf("expected an invalid gRPC code error, got %q", resp.GetError())
+	}
+}
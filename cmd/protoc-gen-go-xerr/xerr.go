@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/nduyhai/xerr/catalog"
+	xerrpb "github.com/nduyhai/xerr/proto/xerr"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// generateFile generates the _xerr.pb.go file of a proto file, if it declares error enums.
+func generateFile(gen *protogen.Plugin, f *protogen.File) error {
+	c := fileCatalog(f)
+	if len(c.Errors) == 0 {
+		return nil
+	}
+	src, err := catalog.GenerateGo(c, catalog.GoOptions{
+		Package:   string(f.GoPackageName),
+		Source:    f.Desc.Path(),
+		Generator: "protoc-gen-go-xerr",
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Desc.Path(), err)
+	}
+	g := gen.NewGeneratedFile(f.GeneratedFilenamePrefix+"_xerr.pb.go", f.GoImportPath)
+	_, err = g.Write(src)
+	return err
+}
+
+// fileCatalog converts the error enums of a proto file, including nested ones, to a catalog.
+func fileCatalog(f *protogen.File) *catalog.Catalog {
+	c := &catalog.Catalog{
+		Domain: proto.GetExtension(f.Desc.Options(), xerrpb.E_Domain).(string),
+	}
+	for _, enum := range allEnums(f.Enums, f.Messages) {
+		c.Errors = append(c.Errors, enumEntries(enum)...)
+	}
+	return c
+}
+
+// allEnums returns the given enums followed by those nested in the messages, recursively.
+func allEnums(enums []*protogen.Enum, messages []*protogen.Message) []*protogen.Enum {
+	for _, m := range messages {
+		enums = allEnums(append(enums, m.Enums...), m.Messages)
+	}
+	return enums
+}
+
+// enumEntries returns the catalog entries of an enum.
+//
+// An enum declares errors if it has the (xerr.defaults) option or if any of its values
+// has the (xerr.error) option. All its values are then errors, except the zero value
+// unless it is explicitly annotated.
+func enumEntries(enum *protogen.Enum) []catalog.Entry {
+	defaults, hasDefaults := errorOptions(enum.Desc.Options(), xerrpb.E_Defaults)
+	annotated := false
+	for _, v := range enum.Values {
+		_, ok := errorOptions(v.Desc.Options(), xerrpb.E_Error)
+		annotated = annotated || ok
+	}
+	if !hasDefaults && !annotated {
+		return nil
+	}
+
+	var entries []catalog.Entry
+	for _, v := range enum.Values {
+		opts, ok := errorOptions(v.Desc.Options(), xerrpb.E_Error)
+		if v.Desc.Number() == 0 && !ok {
+			continue
+		}
+		e := catalog.Entry{
+			Code:        string(v.Desc.Name()),
+			Description: strings.TrimSpace(string(v.Comments.Leading)),
+		}
+		applyOptions(&e, defaults)
+		applyOptions(&e, opts)
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// errorOptions returns the ErrorOptions extension of descriptor options.
+func errorOptions(opts proto.Message, xt protoreflect.ExtensionType) (*xerrpb.ErrorOptions, bool) {
+	if !proto.HasExtension(opts, xt) {
+		return nil, false
+	}
+	return proto.GetExtension(opts, xt).(*xerrpb.ErrorOptions), true
+}
+
+// applyOptions sets the fields of e that are set in opts.
+func applyOptions(e *catalog.Entry, opts *xerrpb.ErrorOptions) {
+	if opts == nil {
+		return
+	}
+	if opts.GetHttp() != 0 {
+		e.HTTP = int(opts.GetHttp())
+	}
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	set(&e.GRPC, opts.GetGrpc())
+	set(&e.Message, opts.GetMessage())
+	set(&e.Reason, opts.GetReason())
+	set(&e.Retry, opts.GetRetry())
+	set(&e.RetryDelay, opts.GetRetryDelay())
+	set(&e.Severity, opts.GetSeverity())
+	set(&e.DocsURL, opts.GetDocsUrl())
+	if len(opts.GetParams()) > 0 {
+		if e.Params == nil {
+			e.Params = map[string]string{}
+		}
+		maps.Copy(e.Params, opts.GetParams())
+	}
+}
@@ -0,0 +1,87 @@
+// Command xerrgen generates Go error definitions and their documentation from a YAML or
+// JSON error catalog.
+//
+// Usage:
+//
+//	xerrgen [-pkg name] [-out file] catalog.yaml
+//	xerrgen -format markdown|html [-title title] [-out file] catalog.yaml
+//	xerrgen -validate catalog.yaml
+//
+// For every catalog entry, the generated file declares a code constant, a definition
+// registered with xerr.Define, type-safe New and Wrap constructors and an Is matcher.
+// The package defaults to $GOPACKAGE, so that xerrgen can be used with go generate:
+//
+//	//go:generate go run github.com/nduyhai/xerr/cmd/xerrgen -out errors_gen.go errors.yaml
+//
+// With -format markdown or html, xerrgen generates a reference page of the errors
+// instead, see catalog.GenerateMarkdown.
+//
+// See the catalog package for the catalog format.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nduyhai/xerr/catalog"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "xerrgen:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the command line and generates the output.
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("xerrgen", flag.ContinueOnError)
+	pkg := flags.String("pkg", os.Getenv("GOPACKAGE"), "Go package of the generated file (default $GOPACKAGE or the catalog package)")
+	out := flags.String("out", "", "output file (default standard output)")
+	format := flags.String("format", "go", "output format: go, markdown or html")
+	title := flags.String("title", catalog.DefaultDocsTitle, "title of the markdown or html documentation")
+	validate := flags.Bool("validate", false, "only validate the catalog")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: xerrgen [-format go|markdown|html] [-pkg name] [-title title] [-out file] [-validate] catalog.yaml|catalog.json")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected one catalog file, got %d", flags.NArg())
+	}
+	in := flags.Arg(0)
+
+	c, err := catalog.Load(in)
+	if err != nil {
+		return err
+	}
+	if *validate {
+		return nil
+	}
+
+	var src []byte
+	switch *format {
+	case "go":
+		src, err = catalog.GenerateGo(c, catalog.GoOptions{Package: *pkg, Source: filepath.Base(in)})
+	case "markdown", "md":
+		src, err = catalog.GenerateMarkdown(c, catalog.DocsOptions{Title: *title})
+	case "html":
+		src, err = catalog.GenerateHTML(c, catalog.DocsOptions{Title: *title})
+	default:
+		err = fmt.Errorf("unknown format %q: expected go, markdown or html", *format)
+	}
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
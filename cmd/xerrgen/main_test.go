@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	const in = "../../samples/catalog/errors.yaml"
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-pkg", "payments", in}, "package payments\n"},
+		{[]string{"-format", "markdown", "-title", "Payment Errors", in}, "# Payment Errors\n"},
+		{[]string{"-format", "html", in}, "<title>Error Reference</title>"},
+	}
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		if err := run(tt.args, &stdout); err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		if !strings.Contains(stdout.String(), tt.want) {
+			t.Fatalf("%v: expected %q in:\n%s", tt.args, tt.want, stdout.String())
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "errors_gen.go")
+	if err := run([]string{"-pkg", "payments", "-out", out, in}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if src, err := os.ReadFile(out); err != nil || !bytes.Contains(src, []byte("func NewPaymentDeclined(")) {
+		t.Fatalf("expected generated constructors in %s, got %v", out, err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"-format", "pdf", "../../samples/catalog/errors.yaml"},
+		{"missing.yaml"},
+	} {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Fatalf("%v: expected an error", args)
+		}
+	}
+}
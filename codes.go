@@ -0,0 +1,287 @@
+package xerr
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Code is a machine-readable error code from the standard code catalog.
+// Each standard code carries a default HTTP status, gRPC code and message,
+// which are used by New, NewStandardError and WriteCodeHTTPError.
+//
+// Codes are hierarchical: segments are separated by CodeSeparator, as in
+// "AUTH.USER.INVALID_PASSWORD". A code inherits the defaults of its nearest
+// registered ancestor, and a Code can be used as an errors.Is target that matches
+// errors with that code or any of its descendants:
+//
+//	errors.Is(err, xerr.Code("AUTH.USER")) // true for AUTH.USER.INVALID_PASSWORD
+type Code string
+
+// CodeSeparator separates the segments of a hierarchical code.
+const CodeSeparator = "."
+
+// General errors.
+const (
+	// UNKNOWN is used when the error cannot be classified.
+	UNKNOWN Code = "UNKNOWN"
+	// INTERNAL is used for unexpected server-side failures.
+	INTERNAL Code = "INTERNAL"
+	// UNAVAILABLE is used when a service or dependency is temporarily unavailable.
+	UNAVAILABLE Code = "UNAVAILABLE"
+	// TIMEOUT is used when an operation did not complete in time.
+	TIMEOUT Code = "TIMEOUT"
+	// CANCELLED is used when an operation was cancelled by the caller.
+	CANCELLED Code = "CANCELLED"
+	// UNIMPLEMENTED is used when an operation is not implemented or supported.
+	UNIMPLEMENTED Code = "UNIMPLEMENTED"
+)
+
+// Client errors.
+const (
+	// INVALID_ARGUMENT is used when the caller supplied an invalid argument.
+	INVALID_ARGUMENT Code = "INVALID_ARGUMENT"
+	// FAILED_PRECONDITION is used when the system is not in a state required for the operation.
+	FAILED_PRECONDITION Code = "FAILED_PRECONDITION"
+	// OUT_OF_RANGE is used when a value is outside the valid range.
+	OUT_OF_RANGE Code = "OUT_OF_RANGE"
+	// UNAUTHENTICATED is used when the request lacks valid credentials.
+	UNAUTHENTICATED Code = "UNAUTHENTICATED"
+	// PERMISSION_DENIED is used when the caller is not allowed to perform the operation.
+	PERMISSION_DENIED Code = "PERMISSION_DENIED"
+	// NOT_FOUND is used when a requested resource does not exist.
+	NOT_FOUND Code = "NOT_FOUND"
+	// ALREADY_EXISTS is used when the resource the caller tried to create already exists.
+	ALREADY_EXISTS Code = "ALREADY_EXISTS"
+	// RESOURCE_EXHAUSTED is used when a quota or rate limit has been exceeded.
+	RESOURCE_EXHAUSTED Code = "RESOURCE_EXHAUSTED"
+	// ABORTED is used when an operation was aborted, typically due to a concurrency issue.
+	ABORTED Code = "ABORTED"
+)
+
+// Data errors.
+const (
+	// DATA_LOSS is used for unrecoverable data loss or corruption.
+	DATA_LOSS Code = "DATA_LOSS"
+	// DATA_VALIDATION is used when data fails semantic validation.
+	DATA_VALIDATION Code = "DATA_VALIDATION"
+)
+
+// Business logic errors.
+const (
+	// BUSINESS_RULE is used when an operation violates a business rule.
+	BUSINESS_RULE Code = "BUSINESS_RULE"
+	// CONFLICT is used when an operation conflicts with the current state of a resource.
+	CONFLICT Code = "CONFLICT"
+)
+
+// CodeInfo describes the defaults associated with a Code in the catalog.
+type CodeInfo struct {
+	Code     Code       // The error code
+	HTTPCode int        // Default HTTP status code
+	GRPCCode codes.Code // Default gRPC status code
+	Message  string     // Default developer-facing message
+	Severity Severity   // Default severity; derived from GRPCCode when unspecified
+}
+
+// standardCodes is the catalog of standard error codes.
+var standardCodes = map[Code]CodeInfo{
+	UNKNOWN:             {UNKNOWN, http.StatusInternalServerError, codes.Unknown, "Unknown error", SeverityError},
+	INTERNAL:            {INTERNAL, http.StatusInternalServerError, codes.Internal, "Internal server error", SeverityError},
+	UNAVAILABLE:         {UNAVAILABLE, http.StatusServiceUnavailable, codes.Unavailable, "Service unavailable", SeverityError},
+	TIMEOUT:             {TIMEOUT, http.StatusGatewayTimeout, codes.DeadlineExceeded, "Request timeout", SeverityWarning},
+	CANCELLED:           {CANCELLED, 499, codes.Canceled, "Request cancelled", SeverityDebug},
+	UNIMPLEMENTED:       {UNIMPLEMENTED, http.StatusNotImplemented, codes.Unimplemented, "Not implemented", SeverityWarning},
+	INVALID_ARGUMENT:    {INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument, "Invalid argument", SeverityInfo},
+	FAILED_PRECONDITION: {FAILED_PRECONDITION, http.StatusBadRequest, codes.FailedPrecondition, "Failed precondition", SeverityInfo},
+	OUT_OF_RANGE:        {OUT_OF_RANGE, http.StatusBadRequest, codes.OutOfRange, "Value out of range", SeverityInfo},
+	UNAUTHENTICATED:     {UNAUTHENTICATED, http.StatusUnauthorized, codes.Unauthenticated, "Unauthenticated request", SeverityWarning},
+	PERMISSION_DENIED:   {PERMISSION_DENIED, http.StatusForbidden, codes.PermissionDenied, "Permission denied", SeverityWarning},
+	NOT_FOUND:           {NOT_FOUND, http.StatusNotFound, codes.NotFound, "Resource not found", SeverityInfo},
+	ALREADY_EXISTS:      {ALREADY_EXISTS, http.StatusConflict, codes.AlreadyExists, "Resource already exists", SeverityInfo},
+	RESOURCE_EXHAUSTED:  {RESOURCE_EXHAUSTED, http.StatusTooManyRequests, codes.ResourceExhausted, "Resource quota exceeded", SeverityWarning},
+	ABORTED:             {ABORTED, http.StatusConflict, codes.Aborted, "Operation aborted", SeverityInfo},
+	DATA_LOSS:           {DATA_LOSS, http.StatusInternalServerError, codes.DataLoss, "Unrecoverable data loss or corruption", SeverityCritical},
+	DATA_VALIDATION:     {DATA_VALIDATION, http.StatusUnprocessableEntity, codes.InvalidArgument, "Data validation error", SeverityInfo},
+	BUSINESS_RULE:       {BUSINESS_RULE, http.StatusUnprocessableEntity, codes.FailedPrecondition, "Business rule violation", SeverityInfo},
+	CONFLICT:            {CONFLICT, http.StatusConflict, codes.Aborted, "Conflict with current state", SeverityInfo},
+}
+
+// codeRegistry holds the standard codes and the codes added with RegisterCode.
+var codeRegistry = struct {
+	sync.RWMutex
+	codes map[Code]CodeInfo
+}{codes: make(map[Code]CodeInfo)}
+
+func init() {
+	for code, info := range standardCodes {
+		codeRegistry.codes[code] = info
+	}
+}
+
+// RegisterCode adds a code to the catalog, so that it and its descendants
+// resolve to the given defaults.
+// It returns an error if the code is empty or already registered.
+func RegisterCode(info CodeInfo) error {
+	if info.Code == "" {
+		return errors.New("xerr: cannot register an empty code")
+	}
+
+	codeRegistry.Lock()
+	defer codeRegistry.Unlock()
+	if _, ok := codeRegistry.codes[info.Code]; ok {
+		return errors.New("xerr: code " + string(info.Code) + " is already registered")
+	}
+	codeRegistry.codes[info.Code] = info
+	return nil
+}
+
+// unregisterCode removes a code added with RegisterCode from the catalog.
+// Standard codes are never removed.
+func unregisterCode(code Code) {
+	if _, ok := standardCodes[code]; ok {
+		return
+	}
+	codeRegistry.Lock()
+	defer codeRegistry.Unlock()
+	delete(codeRegistry.codes, code)
+}
+
+// LookupCode returns the catalog entry registered for exactly the given code.
+// The second return value reports whether the code is part of the catalog.
+func LookupCode(code Code) (CodeInfo, bool) {
+	codeRegistry.RLock()
+	defer codeRegistry.RUnlock()
+	info, ok := codeRegistry.codes[code]
+	return info, ok
+}
+
+// ResolveCode returns the catalog entry for the code or its nearest registered ancestor.
+// The Code field of the returned entry is set to the given code.
+// The second return value is false if neither the code nor any ancestor is registered.
+func ResolveCode(code Code) (CodeInfo, bool) {
+	for c := code; c != ""; c = c.Parent() {
+		if info, ok := LookupCode(c); ok {
+			info.Code = code
+			return info, true
+		}
+	}
+	return CodeInfo{Code: code}, false
+}
+
+// RegisteredCodes returns all codes in the catalog, including the standard codes, sorted by code.
+func RegisteredCodes() []CodeInfo {
+	codeRegistry.RLock()
+	infos := make([]CodeInfo, 0, len(codeRegistry.codes))
+	for _, info := range codeRegistry.codes {
+		infos = append(infos, info)
+	}
+	codeRegistry.RUnlock()
+
+	sortCodeInfos(infos)
+	return infos
+}
+
+// sortCodeInfos sorts catalog entries by code.
+func sortCodeInfos(infos []CodeInfo) {
+	slices.SortFunc(infos, func(a, b CodeInfo) int {
+		return strings.Compare(string(a.Code), string(b.Code))
+	})
+}
+
+// StandardCodes returns all codes in the standard catalog, sorted by code.
+func StandardCodes() []CodeInfo {
+	infos := make([]CodeInfo, 0, len(standardCodes))
+	for _, info := range standardCodes {
+		infos = append(infos, info)
+	}
+	sortCodeInfos(infos)
+	return infos
+}
+
+// String returns the code as a string.
+func (c Code) String() string {
+	return string(c)
+}
+
+// Error implements the error interface, so that a Code can be used as an errors.Is target.
+func (c Code) Error() string {
+	return string(c)
+}
+
+// Parent returns the code without its last segment, or an empty code for a top-level code.
+func (c Code) Parent() Code {
+	i := strings.LastIndex(string(c), CodeSeparator)
+	if i < 0 {
+		return ""
+	}
+	return c[:i]
+}
+
+// Segments returns the segments of the code.
+func (c Code) Segments() []string {
+	if c == "" {
+		return nil
+	}
+	return strings.Split(string(c), CodeSeparator)
+}
+
+// Depth returns the number of segments of the code.
+func (c Code) Depth() int {
+	if c == "" {
+		return 0
+	}
+	return strings.Count(string(c), CodeSeparator) + 1
+}
+
+// Within reports whether the code equals ancestor or is one of its descendants.
+func (c Code) Within(ancestor Code) bool {
+	if ancestor == "" {
+		return false
+	}
+	return c == ancestor || strings.HasPrefix(string(c), string(ancestor)+CodeSeparator)
+}
+
+// HTTPCode returns the default HTTP status code for the code,
+// inherited from the nearest registered ancestor if the code itself is not registered.
+// Codes that are not in the catalog map to HTTP 500.
+func (c Code) HTTPCode() int {
+	if info, ok := ResolveCode(c); ok {
+		return info.HTTPCode
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the default gRPC status code for the code,
+// inherited from the nearest registered ancestor if the code itself is not registered.
+// Codes that are not in the catalog map to codes.Unknown.
+func (c Code) GRPCCode() codes.Code {
+	if info, ok := ResolveCode(c); ok {
+		return info.GRPCCode
+	}
+	return codes.Unknown
+}
+
+// DefaultMessage returns the default developer-facing message for the code,
+// inherited from the nearest registered ancestor if the code itself is not registered.
+// Codes that are not in the catalog have no default message.
+func (c Code) DefaultMessage() string {
+	if info, ok := ResolveCode(c); ok {
+		return info.Message
+	}
+	return ""
+}
+
+// Severity returns the default severity for the code,
+// inherited from the nearest registered ancestor if the code itself is not registered.
+// When the catalog entry has no severity, it is derived from the gRPC status code.
+func (c Code) Severity() Severity {
+	if info, ok := ResolveCode(c); ok && info.Severity != SeverityUnspecified {
+		return info.Severity
+	}
+	return SeverityForGRPCCode(c.GRPCCode())
+}
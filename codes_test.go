@@ -0,0 +1,143 @@
+package xerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestNewResolvesCatalogCodes(t *testing.T) {
+	err := New(string(NOT_FOUND), "user not found")
+	if err.GetHTTPCode() != http.StatusNotFound {
+		t.Fatalf("expected HTTP 404, got %d", err.GetHTTPCode())
+	}
+	if err.GetGRPCCode() != codes.NotFound {
+		t.Fatalf("expected codes.NotFound, got %s", err.GetGRPCCode())
+	}
+
+	custom := New("PAYMENT_FAILED", "payment failed")
+	if custom.GetHTTPCode() != http.StatusInternalServerError || custom.GetGRPCCode() != codes.Unknown {
+		t.Fatalf("expected 500/Unknown for unknown code, got %d/%s", custom.GetHTTPCode(), custom.GetGRPCCode())
+	}
+}
+
+func TestNewStandardErrorDefaultMessage(t *testing.T) {
+	err := NewStandardError(UNAVAILABLE, "")
+	if err.GetMessage() != "Service unavailable" {
+		t.Fatalf("expected default message, got %q", err.GetMessage())
+	}
+	if err.GetHTTPCode() != http.StatusServiceUnavailable || err.GetGRPCCode() != codes.Unavailable {
+		t.Fatalf("expected 503/Unavailable, got %d/%s", err.GetHTTPCode(), err.GetGRPCCode())
+	}
+}
+
+func TestWriteStandardHTTPError(t *testing.T) {
+	code := "NOT_FOUND"
+	for name, write := range map[string]func(http.ResponseWriter){
+		"string": func(w http.ResponseWriter) { WriteStandardHTTPError(w, code, "Resource not found") },
+		"Code":   func(w http.ResponseWriter) { WriteCodeHTTPError(w, NOT_FOUND, "Resource not found") },
+	} {
+		rec := httptest.NewRecorder()
+		write(rec)
+
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected status 404, got %d", name, rec.Code)
+		}
+		var body HTTPError
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if body.Code != "NOT_FOUND" || body.Message != "Resource not found" {
+			t.Fatalf("%s: unexpected body: %+v", name, body)
+		}
+	}
+}
+
+func TestStandardCodesConsistentWithConverter(t *testing.T) {
+	for _, info := range StandardCodes() {
+		if info.Message == "" {
+			t.Errorf("%s: missing default message", info.Code)
+		}
+		if got := DefaultConverter.GRPCToHTTP(info.GRPCCode); info.HTTPCode < 500 && got >= 500 {
+			t.Errorf("%s: client error mapped to gRPC code %s which converts to HTTP %d", info.Code, info.GRPCCode, got)
+		}
+	}
+}
+
+func TestCodeHierarchy(t *testing.T) {
+	code := Code("AUTH.USER.INVALID_PASSWORD")
+
+	if code.Parent() != "AUTH.USER" || code.Parent().Parent() != "AUTH" || Code("AUTH").Parent() != "" {
+		t.Fatalf("unexpected parents of %s", code)
+	}
+	if code.Depth() != 3 || Code("").Depth() != 0 {
+		t.Fatalf("unexpected depth %d", code.Depth())
+	}
+	if segments := code.Segments(); len(segments) != 3 || segments[1] != "USER" {
+		t.Fatalf("unexpected segments %v", segments)
+	}
+	if !code.Within("AUTH.USER") || !code.Within(code) || code.Within("AUTH.US") || code.Within("") {
+		t.Fatal("unexpected Within results")
+	}
+}
+
+func TestIsMatchesCodePrefix(t *testing.T) {
+	err := New("AUTH.USER.INVALID_PASSWORD", "invalid password")
+	wrapped := fmt.Errorf("login: %w", err)
+
+	for _, target := range []Code{"AUTH", "AUTH.USER", "AUTH.USER.INVALID_PASSWORD"} {
+		if !errors.Is(wrapped, target) {
+			t.Errorf("expected errors.Is to match %s", target)
+		}
+	}
+	for _, target := range []Code{"AUTH.USERS", "AUTH.USER.INVALID_PASSWORD.EXTRA", "BILLING"} {
+		if errors.Is(wrapped, target) {
+			t.Errorf("expected errors.Is not to match %s", target)
+		}
+	}
+	if !errors.Is(NewStandardError(NOT_FOUND, ""), NOT_FOUND) {
+		t.Error("expected standard code to match itself")
+	}
+}
+
+// registerCode registers a code in the catalog for the duration of the test.
+func registerCode(t *testing.T, info CodeInfo) {
+	t.Helper()
+	if err := RegisterCode(info); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { unregisterCode(info.Code) })
+}
+
+func TestChildCodesInheritFromAncestor(t *testing.T) {
+	registerCode(t, CodeInfo{Code: "TEST_AUTH", HTTPCode: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated, Message: "Authentication failed"})
+	if err := RegisterCode(CodeInfo{Code: "TEST_AUTH"}); err == nil {
+		t.Fatal("expected duplicate registration to fail")
+	}
+
+	err := New("TEST_AUTH.USER.INVALID_PASSWORD", "")
+	if err.GetHTTPCode() != http.StatusUnauthorized || err.GetGRPCCode() != codes.Unauthenticated {
+		t.Fatalf("expected inherited 401/Unauthenticated, got %d/%s", err.GetHTTPCode(), err.GetGRPCCode())
+	}
+	if err.GetMessage() != "Authentication failed" {
+		t.Fatalf("expected inherited message, got %q", err.GetMessage())
+	}
+
+	notFound := New("NOT_FOUND.USER", "user not found")
+	if notFound.GetHTTPCode() != http.StatusNotFound {
+		t.Fatalf("expected child of NOT_FOUND to inherit 404, got %d", notFound.GetHTTPCode())
+	}
+
+	info, ok := ResolveCode("TEST_AUTH.USER")
+	if !ok || info.Code != "TEST_AUTH.USER" || info.HTTPCode != http.StatusUnauthorized {
+		t.Fatalf("unexpected resolved info %+v", info)
+	}
+	if _, ok := LookupCode("TEST_AUTH.USER"); ok {
+		t.Fatal("expected LookupCode to match registered codes only")
+	}
+}
@@ -0,0 +1,262 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Definition describes a reusable error: its code, default HTTP and gRPC status codes,
+// domain, message template and user reason. Errors are created from it with New and Wrap,
+// and the Definition itself is an errors.Is target that matches any of its instances:
+//
+//	var ErrUserNotFound = xerr.Define("USER_NOT_FOUND",
+//		xerr.WithHTTPStatus(http.StatusNotFound),
+//		xerr.WithMessage("user %d not found"),
+//	)
+//
+//	err := ErrUserNotFound.New(42)
+//	errors.Is(err, ErrUserNotFound) // true
+type Definition struct {
+	template *StructuredError
+}
+
+// NewDefinition creates a Definition without registering it.
+// The status codes and message default to those of the code in the catalog
+// and can be overridden with options. When the options set only one of the HTTP and
+// gRPC status codes, the other one is derived from it with the DefaultConverter,
+// instead of being taken from the code catalog.
+func NewDefinition(code Code, opts ...Option) *Definition {
+	return newDefinition(code, DefaultConverter, opts)
+}
+
+// unsetGRPCCode marks a gRPC code that was not set by the options of a definition.
+const unsetGRPCCode = codes.Code(^uint32(0))
+
+// newDefinition implements NewDefinition, deriving a missing status code with converter.
+func newDefinition(code Code, converter CodeConverter, opts []Option) *Definition {
+	// Apply the options to a probe to find out which status codes they set
+	probe := &StructuredError{GRPCCode: unsetGRPCCode}
+	probe.apply(opts)
+
+	template := &StructuredError{
+		reason:   NewDefaultReason(string(code), code.DefaultMessage()),
+		GRPCCode: code.GRPCCode(),
+		HTTPCode: code.HTTPCode(),
+	}
+	template.apply(opts)
+	switch httpSet, grpcSet := probe.HTTPCode != 0, probe.GRPCCode != unsetGRPCCode; {
+	case httpSet && !grpcSet:
+		template.GRPCCode = converter.HTTPToGRPC(probe.HTTPCode)
+	case grpcSet && !httpSet:
+		template.HTTPCode = converter.GRPCToHTTP(probe.GRPCCode)
+	}
+	return &Definition{template: template}
+}
+
+// Define creates a Definition and registers it in the DefaultRegistry.
+// It panics if a definition with the same code is already registered,
+// so it is meant to be used in package-level variable declarations.
+//
+// Unless the code is already in the code catalog, Define also adds it with the status codes
+// and severity of the definition, so that its child codes inherit them, see RegisterCode.
+func Define(code Code, opts ...Option) *Definition {
+	def := NewDefinition(code, opts...)
+	if err := DefaultRegistry.Register(def); err != nil {
+		panic(err)
+	}
+	def.registerCode()
+	return def
+}
+
+// registerCode adds the code of the definition to the code catalog, with its status codes
+// and severity, unless the code is already registered. The message is left out, since it
+// may be a template.
+func (d *Definition) registerCode() {
+	_ = RegisterCode(CodeInfo{
+		Code:     d.Code(),
+		HTTPCode: d.HTTPCode(),
+		GRPCCode: d.GRPCCode(),
+		Severity: d.template.severity,
+	})
+}
+
+// Code returns the error code of the definition.
+func (d *Definition) Code() Code {
+	return Code(d.template.GetCode())
+}
+
+// HTTPCode returns the default HTTP status code of the definition.
+func (d *Definition) HTTPCode() int {
+	return d.template.HTTPCode
+}
+
+// GRPCCode returns the default gRPC status code of the definition.
+func (d *Definition) GRPCCode() codes.Code {
+	return d.template.GRPCCode
+}
+
+// Domain returns the domain of the definition.
+func (d *Definition) Domain() string {
+	return d.template.Domain
+}
+
+// Message returns the message template of the definition.
+func (d *Definition) Message() string {
+	return d.template.GetMessage()
+}
+
+// UserReason returns the user-facing reason of the definition.
+func (d *Definition) UserReason() string {
+	return d.template.GetUserReason()
+}
+
+// DocsURL returns the link to the documentation of the definition.
+func (d *Definition) DocsURL() string {
+	return d.template.docsURL
+}
+
+// Severity returns the severity of the errors created from the definition.
+func (d *Definition) Severity() Severity {
+	return d.template.GetSeverity()
+}
+
+// Retryability returns the retry classification of the errors created from the definition.
+func (d *Definition) Retryability() Retryability {
+	return d.template.GetRetryability()
+}
+
+// RetryDelay returns the delay to wait before retrying the errors created from the definition,
+// or zero if there is none.
+func (d *Definition) RetryDelay() time.Duration {
+	return d.template.GetRetryDelay()
+}
+
+// Error implements the error interface, so that a Definition can be used as an errors.Is target.
+func (d *Definition) Error() string {
+	return d.template.GetCode()
+}
+
+// New creates an error from the definition.
+// The message template is formatted with args as with fmt.Sprintf, unless the only
+// argument is a Params: then the message and user reason are rendered as templates
+// with named parameters, see TemplateReason.
+func (d *Definition) New(args ...any) Error {
+	return d.instance(nil, d.template.GetMessage(), args)
+}
+
+// Wrap creates an error from the definition with err as its cause.
+// The message template is formatted with args as in New; if the definition
+// has no message, the message of err is used.
+// It returns nil if err is nil.
+func (d *Definition) Wrap(err error, args ...any) Error {
+	if err == nil {
+		return nil
+	}
+	message := d.template.GetMessage()
+	if message == "" {
+		message = err.Error()
+	}
+	return d.instance(err, message, args)
+}
+
+// instance creates a new error from the definition template.
+func (d *Definition) instance(cause error, message string, args []any) *StructuredError {
+	e := d.template.clone()
+	e.reason = d.reason(message, args)
+	e.Cause = cause
+	e.stack = captureStack(d.stackMode())
+	return e
+}
+
+// stackMode returns the stack capture mode of the errors created from the definition.
+func (d *Definition) stackMode() StackMode {
+	if d.template.stackMode != nil {
+		return *d.template.stackMode
+	}
+	return DefaultStackMode
+}
+
+// reason renders the message template with args.
+func (d *Definition) reason(message string, args []any) Reason {
+	code := d.template.GetCode()
+	userReason := d.template.GetUserReason()
+	if len(args) == 1 {
+		if params, ok := args[0].(Params); ok {
+			return NewTemplateReason(code, message, params).WithReason(userReason)
+		}
+	}
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	return NewDefaultReason(code, message).WithReason(userReason)
+}
+
+// Registry is a set of definitions with unique codes.
+type Registry struct {
+	mu          sync.RWMutex
+	definitions map[Code]*Definition
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{definitions: make(map[Code]*Definition)}
+}
+
+// DefaultRegistry is the process-wide registry used by Define.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a definition to the registry.
+// It returns an error if the code is empty or a definition with the same code is already registered.
+func (r *Registry) Register(def *Definition) error {
+	code := def.Code()
+	if code == "" {
+		return errors.New("xerr: cannot register a definition with an empty code")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.definitions[code]; ok {
+		return errors.New("xerr: definition " + string(code) + " is already registered")
+	}
+	r.definitions[code] = def
+	return nil
+}
+
+// Lookup returns the definition registered for code.
+func (r *Registry) Lookup(code Code) (*Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.definitions[code]
+	return def, ok
+}
+
+// Definitions returns all registered definitions, sorted by code.
+func (r *Registry) Definitions() []*Definition {
+	r.mu.RLock()
+	defs := make([]*Definition, 0, len(r.definitions))
+	for _, def := range r.definitions {
+		defs = append(defs, def)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(defs, func(a, b *Definition) int {
+		return strings.Compare(string(a.Code()), string(b.Code()))
+	})
+	return defs
+}
+
+// LookupDefinition returns the definition registered for code in the DefaultRegistry.
+func LookupDefinition(code Code) (*Definition, bool) {
+	return DefaultRegistry.Lookup(code)
+}
+
+// Definitions returns all definitions registered in the DefaultRegistry, sorted by code.
+func Definitions() []*Definition {
+	return DefaultRegistry.Definitions()
+}
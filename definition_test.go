@@ -0,0 +1,175 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+var errTestUserNotFound = Define("TEST_USER_NOT_FOUND",
+	WithHTTPStatus(http.StatusNotFound),
+	WithGRPCStatus(codes.NotFound),
+	WithDomain("users.example.com"),
+	WithMessage("user %d not found"),
+	WithUserReason("We could not find your account"),
+)
+
+func TestDefinitionNew(t *testing.T) {
+	err := errTestUserNotFound.New(42)
+
+	if err.GetCode() != "TEST_USER_NOT_FOUND" || err.GetMessage() != "user 42 not found" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err.GetHTTPCode() != http.StatusNotFound || err.GetGRPCCode() != codes.NotFound {
+		t.Fatalf("unexpected status %d/%s", err.GetHTTPCode(), err.GetGRPCCode())
+	}
+	if err.GetUserReason() != "We could not find your account" {
+		t.Fatalf("unexpected user reason %q", err.GetUserReason())
+	}
+	if err.(*StructuredError).GetErrorInfo().Domain != "users.example.com" {
+		t.Fatal("expected domain from the definition")
+	}
+	if errTestUserNotFound.Message() != "user %d not found" {
+		t.Fatal("expected definition template to be unchanged")
+	}
+}
+
+func TestDefinitionDefaultsFromCatalog(t *testing.T) {
+	def := NewDefinition("NOT_FOUND.ORDER")
+	err := def.New()
+	if err.GetHTTPCode() != http.StatusNotFound || err.GetMessage() != "Resource not found" {
+		t.Fatalf("expected defaults inherited from NOT_FOUND, got %d %q", err.GetHTTPCode(), err.GetMessage())
+	}
+}
+
+func TestDefinitionDerivesStatusCodes(t *testing.T) {
+	grpcOnly := NewDefinition("TEST_ACCOUNT.LOCKED", WithGRPCStatus(codes.FailedPrecondition))
+	if grpcOnly.HTTPCode() != http.StatusBadRequest || grpcOnly.GRPCCode() != codes.FailedPrecondition {
+		t.Fatalf("expected 400/FailedPrecondition, got %d/%s", grpcOnly.HTTPCode(), grpcOnly.GRPCCode())
+	}
+	if IsServerError(grpcOnly.New()) {
+		t.Fatal("expected a gRPC-only client error not to be a server error")
+	}
+
+	httpOnly := NewDefinition("NOT_FOUND.TEST_ARCHIVED", WithHTTPStatus(http.StatusConflict))
+	if httpOnly.HTTPCode() != http.StatusConflict || httpOnly.GRPCCode() != codes.Aborted {
+		t.Fatalf("expected 409/Aborted, got %d/%s", httpOnly.HTTPCode(), httpOnly.GRPCCode())
+	}
+}
+
+func TestDefinitionWrap(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+	err := errTestUserNotFound.Wrap(cause, 7)
+
+	if err.GetMessage() != "user 7 not found" || !errors.Is(err, cause) {
+		t.Fatalf("unexpected wrapped error %v", err)
+	}
+	if errTestUserNotFound.Wrap(nil) != nil {
+		t.Fatal("expected nil when wrapping nil")
+	}
+
+	plain := NewDefinition("TEST_PLAIN", WithMessage(""))
+	if got := plain.Wrap(cause).GetMessage(); got != cause.Error() {
+		t.Fatalf("expected message of the cause, got %q", got)
+	}
+}
+
+func TestDefinitionIs(t *testing.T) {
+	err := fmt.Errorf("handler: %w", errTestUserNotFound.New(1))
+	if !errors.Is(err, errTestUserNotFound) {
+		t.Fatal("expected instance to match its definition")
+	}
+	if errors.Is(New("OTHER", "other"), errTestUserNotFound) {
+		t.Fatal("expected other errors not to match the definition")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	b := NewDefinition("B")
+	a := NewDefinition("A")
+
+	if err := registry.Register(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Register(a); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Register(NewDefinition("A")); err == nil {
+		t.Fatal("expected duplicate code to be rejected")
+	}
+
+	defs := registry.Definitions()
+	if len(defs) != 2 || defs[0] != a || defs[1] != b {
+		t.Fatalf("unexpected definitions %v", defs)
+	}
+	if def, ok := registry.Lookup("B"); !ok || def != b {
+		t.Fatal("expected to find B")
+	}
+}
+
+func TestDefinePanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Define to panic on a duplicate code")
+		}
+	}()
+	Define("TEST_USER_NOT_FOUND")
+}
+
+func TestDefineRegistersCode(t *testing.T) {
+	child := Code("TEST_USER_NOT_FOUND.DELETED")
+	if child.HTTPCode() != http.StatusNotFound || child.GRPCCode() != codes.NotFound || child.Severity() != SeverityInfo {
+		t.Fatalf("expected child codes to inherit the definition, got %d/%s/%s", child.HTTPCode(), child.GRPCCode(), child.Severity())
+	}
+	if NewStandardError(child, "user 42 was deleted").GetHTTPCode() != http.StatusNotFound {
+		t.Fatal("expected errors with a child code to get the status of the definition")
+	}
+
+	// Codes already in the catalog keep their entry
+	f := newTestFactory()
+	f.Define(NOT_FOUND, WithHTTPStatus(http.StatusGone))
+	if NOT_FOUND.HTTPCode() != http.StatusNotFound {
+		t.Fatal("expected the standard entry to be kept")
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	if def, ok := LookupDefinition("TEST_USER_NOT_FOUND"); !ok || def != errTestUserNotFound {
+		t.Fatal("expected Define to register in the default registry")
+	}
+	found := false
+	for _, def := range Definitions() {
+		found = found || def == errTestUserNotFound
+	}
+	if !found {
+		t.Fatal("expected Definitions to list the definition")
+	}
+}
+
+func TestDocsURLRoundTrip(t *testing.T) {
+	const url = "https://docs.example.com/errors/test-docs"
+	def := NewDefinition("TEST_DOCS", WithHTTPStatus(http.StatusConflict), WithDocsURL(url))
+	if def.DocsURL() != url {
+		t.Fatalf("expected definition docs URL %q, got %q", url, def.DocsURL())
+	}
+	err := def.New().(*StructuredError)
+	if err.GetDocsURL() != url || err.GetHelp().GetLinks()[0].GetUrl() != url {
+		t.Fatalf("expected instance docs URL %q, got %q", url, err.GetDocsURL())
+	}
+
+	body, status := err.ToHTTPJSON()
+	decoded, decodeErr := FromHTTPJSON(body, status)
+	if decodeErr != nil {
+		t.Fatal(decodeErr)
+	}
+	if got := decoded.(*StructuredError).GetDocsURL(); got != url {
+		t.Fatalf("expected docs URL to survive HTTP, got %q from %s", got, body)
+	}
+	if got := FromGRPCStatus(err.ToGRPCStatus()).(*StructuredError).GetDocsURL(); got != url {
+		t.Fatalf("expected docs URL to survive gRPC, got %q", got)
+	}
+}
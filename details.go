@@ -4,55 +4,45 @@ import (
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 )
 
-// WithErrorInfo adds ErrorInfo detail to the structured error.
+// WithErrorInfo returns a copy of the structured error with ErrorInfo details.
 // ErrorInfo is a standard gRPC error detail that provides structured error information.
 func (e *StructuredError) WithErrorInfo(domain string, metadata map[string]string) Error {
-	// Store domain and metadata in the error
-	e.Domain = domain
-	if metadata != nil {
-		if e.Metadata == nil {
-			e.Metadata = make(map[string]string)
-		}
-		for k, v := range metadata {
-			e.Metadata[k] = v
-		}
-	}
-
-	return e
+	return e.with(WithErrorInfo(domain, metadata))
 }
 
-// WithBadRequest adds field violations to the error.
+// WithBadRequest returns a copy of the error with field violations added.
 // This is useful for validation errors where multiple fields have issues.
 func (e *StructuredError) WithBadRequest(fieldViolations map[string]string) Error {
-	// Store field violations in metadata with a special prefix
-	if fieldViolations != nil {
-		if e.Metadata == nil {
-			e.Metadata = make(map[string]string)
-		}
-		for field, description := range fieldViolations {
-			e.Metadata["field:"+field] = description
-		}
-	}
-
-	return e
+	return e.with(WithBadRequest(fieldViolations))
 }
 
+// defaultDomain is the ErrorInfo domain used when an error has no domain.
+const defaultDomain = "github.com/nduyhai/xerr"
+
 // GetErrorInfo extracts ErrorInfo from the structured error.
 // This is used when converting to gRPC status.
+// Typed metadata values and the parameters of a ParamsReason are string-encoded, see Value.String.
 func (e *StructuredError) GetErrorInfo() *errdetails.ErrorInfo {
 	domain := e.Domain
 	if domain == "" {
-		domain = "github.com/nduyhai/xerr"
+		domain = defaultDomain
 	}
 	return &errdetails.ErrorInfo{
 		Reason:   e.GetCode(),
 		Domain:   domain,
-		Metadata: e.Metadata,
+		Metadata: stringMetadata(publicMetadata(e, e.allMetadata())),
 	}
 }
 
+// publicStringMetadata returns the metadata of the error that is public under its redaction
+// policies, in its string encoding.
+func (e *StructuredError) publicStringMetadata() map[string]string {
+	return stringMetadata(publicMetadata(e, typedMetadata(e.Metadata, e.values)))
+}
+
 // GetBadRequest extracts BadRequest field violations from the structured error.
 // This is used when converting to gRPC status.
+// Violations that are not public under the redaction policies of the error are left out or masked.
 func (e *StructuredError) GetBadRequest() *errdetails.BadRequest {
 	if e.Metadata == nil {
 		return nil
@@ -61,7 +51,7 @@ func (e *StructuredError) GetBadRequest() *errdetails.BadRequest {
 	var fieldViolations []*errdetails.BadRequest_FieldViolation
 
 	// Extract field violations from metadata
-	for k, v := range e.Metadata {
+	for k, v := range e.publicStringMetadata() {
 		if len(k) > 6 && k[:6] == "field:" {
 			field := k[6:] // Remove "field:" prefix
 			fieldViolations = append(fieldViolations, &errdetails.BadRequest_FieldViolation{
@@ -80,24 +70,15 @@ func (e *StructuredError) GetBadRequest() *errdetails.BadRequest {
 	}
 }
 
-// WithPreconditionFailure adds precondition failures to the error.
+// WithPreconditionFailure returns a copy of the error with precondition failures added.
 // This is useful for errors where certain preconditions were not met.
 func (e *StructuredError) WithPreconditionFailure(violations map[string]string) Error {
-	// Store precondition violations in metadata with a special prefix
-	if violations != nil {
-		if e.Metadata == nil {
-			e.Metadata = make(map[string]string)
-		}
-		for condition, description := range violations {
-			e.Metadata["precondition:"+condition] = description
-		}
-	}
-
-	return e
+	return e.with(WithPreconditionFailure(violations))
 }
 
 // GetPreconditionFailure extracts PreconditionFailure from the structured error.
 // This is used when converting to gRPC status.
+// Violations that are not public under the redaction policies of the error are left out or masked.
 func (e *StructuredError) GetPreconditionFailure() *errdetails.PreconditionFailure {
 	if e.Metadata == nil {
 		return nil
@@ -106,7 +87,7 @@ func (e *StructuredError) GetPreconditionFailure() *errdetails.PreconditionFailu
 	var violations []*errdetails.PreconditionFailure_Violation
 
 	// Extract precondition violations from metadata
-	for k, v := range e.Metadata {
+	for k, v := range e.publicStringMetadata() {
 		if len(k) > 13 && k[:13] == "precondition:" {
 			condition := k[13:] // Remove "precondition:" prefix
 			violations = append(violations, &errdetails.PreconditionFailure_Violation{
@@ -125,3 +106,25 @@ func (e *StructuredError) GetPreconditionFailure() *errdetails.PreconditionFailu
 		Violations: violations,
 	}
 }
+
+// WithDocsURL returns a copy of the error with a link to its documentation.
+func (e *StructuredError) WithDocsURL(url string) Error {
+	return e.with(WithDocsURL(url))
+}
+
+// GetDocsURL returns the link to the documentation of the error, or an empty string.
+func (e *StructuredError) GetDocsURL() string {
+	return e.docsURL
+}
+
+// GetHelp returns the Help detail linking to the documentation of the error,
+// or nil if it has no documentation link.
+// This is used when converting to gRPC status.
+func (e *StructuredError) GetHelp() *errdetails.Help {
+	if e.docsURL == "" {
+		return nil
+	}
+	return &errdetails.Help{
+		Links: []*errdetails.Help_Link{{Description: "Documentation", Url: e.docsURL}},
+	}
+}
@@ -1,6 +1,10 @@
 package xerr
 
-import "google.golang.org/grpc/codes"
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
 
 // Error is the interface that wraps the basic error functionality.
 // It extends the standard error interface with additional methods for
@@ -22,9 +26,15 @@ type Error interface {
 	// GetHTTPCode returns the HTTP status code.
 	GetHTTPCode() int
 
-	// GetMetadata returns the error metadata.
+	// GetMetadata returns the error metadata with every value in its string encoding.
 	GetMetadata() map[string]string
 
+	// GetMetadataValue returns the typed metadata value for key.
+	GetMetadataValue(key string) (Value, bool)
+
+	// GetMetadataValues returns the typed error metadata.
+	GetMetadataValues() map[string]Value
+
 	// GetCause returns the underlying cause of the error.
 	GetCause() error
 
@@ -39,20 +49,47 @@ type Error interface {
 	// GetUserReason returns the user-facing reason from the Reason.
 	GetUserReason() string
 
-	// Core modifier methods
+	// GetSeverity returns the severity of the error.
+	GetSeverity() Severity
+
+	// GetRetryability returns whether the failed operation can be retried.
+	GetRetryability() Retryability
+
+	// GetRetryDelay returns the delay to wait before retrying, or zero if there is none.
+	GetRetryDelay() time.Duration
+
+	// GetOccurrenceID returns the unique ID of this error instance.
+	GetOccurrenceID() string
 
-	// WithReason adds a user-facing reason to the error.
+	// GetTimestamp returns the time this error instance was created.
+	GetTimestamp() time.Time
+
+	// Core modifier methods: each returns a modified copy and leaves the receiver unchanged
+
+	// WithReason returns a copy of the error with the given user-facing reason.
 	WithReason(reason string) Error
 
-	// WithGRPCCode sets the gRPC status code.
+	// WithGRPCCode returns a copy of the error with the given gRPC status code.
 	WithGRPCCode(code codes.Code) Error
 
-	// WithHTTPCode sets the HTTP status code.
+	// WithHTTPCode returns a copy of the error with the given HTTP status code.
 	WithHTTPCode(code int) Error
 
-	// WithMetadata adds metadata to the error.
+	// WithMetadata returns a copy of the error with the given string metadata entry added.
 	WithMetadata(key string, value string) Error
 
+	// WithMetadataValue returns a copy of the error with the given typed metadata entry added.
+	WithMetadataValue(key string, value any) Error
+
+	// WithSeverity returns a copy of the error with the given severity.
+	WithSeverity(severity Severity) Error
+
+	// WithRetryability returns a copy of the error marking whether the failed operation can be retried.
+	WithRetryability(retry Retryability) Error
+
+	// WithRetryDelay returns a copy of the error marked as retryable after the given delay.
+	WithRetryDelay(delay time.Duration) Error
+
 	// Standard error interface methods
 
 	// Is implements the errors.Is interface for error comparison.
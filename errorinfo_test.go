@@ -8,7 +8,7 @@ func TestWithErrorInfoDomain(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected *StructuredError, got %T", err)
 	}
-	se.WithErrorInfo("example.com", map[string]string{"k": "v"})
+	se = se.WithErrorInfo("example.com", map[string]string{"k": "v"}).(*StructuredError)
 
 	info := se.GetErrorInfo()
 	if info.Domain != "example.com" {
@@ -21,8 +21,7 @@ func TestWithErrorInfoDomain(t *testing.T) {
 
 func TestFromGRPCStatusDomain(t *testing.T) {
 	err := New("CODE", "msg")
-	se := err.(*StructuredError)
-	se.WithErrorInfo("service.domain", nil)
+	se := err.(*StructuredError).WithErrorInfo("service.domain", nil).(*StructuredError)
 	st := se.ToGRPCStatus()
 	converted := FromGRPCStatus(st)
 	se2, ok := converted.(*StructuredError)
@@ -0,0 +1,54 @@
+package xerr
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ExposurePolicy decides which developer-facing messages are sent to clients over HTTP and gRPC.
+// Hidden messages are replaced with a generic one; the occurrence ID is still sent, so that
+// clients can report it and operators can find the full error in the logs, see SetResponseLogger.
+//
+//	xerr.DefaultExposurePolicy = &xerr.ExposurePolicy{Hide: xerr.IsServerError, Message: "Something went wrong"}
+//	xerr.DevelopmentMode = os.Getenv("APP_ENV") == "development"
+type ExposurePolicy struct {
+	Hide    func(err Error) bool // Reports whether the message of err is hidden; nil hides nothing
+	Message string               // Replaces hidden messages; defaults to the text of the HTTP status
+}
+
+// DefaultExposurePolicy is the ExposurePolicy of HTTPError.Message and of gRPC status messages.
+// By default, it hides the messages of server errors, see IsServerError.
+// Setting it to nil sends every message.
+var DefaultExposurePolicy = &ExposurePolicy{Hide: IsServerError}
+
+// DevelopmentMode, when true, sends every message regardless of the DefaultExposurePolicy,
+// for local development. It is false by default, so that server errors don't leak their
+// messages unless it is explicitly enabled.
+var DevelopmentMode bool
+
+// IsServerError reports whether err is a server-side failure: an HTTP status of 500 or above,
+// or the Internal, Unknown or DataLoss gRPC code. It is meant to be used as ExposurePolicy.Hide.
+func IsServerError(err Error) bool {
+	switch err.GetGRPCCode() {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		return true
+	}
+	return err.GetHTTPCode() >= http.StatusInternalServerError
+}
+
+// hides reports whether the policy hides the message of err.
+func (p *ExposurePolicy) hides(err Error) bool {
+	return p != nil && p.Hide != nil && !DevelopmentMode && p.Hide(err)
+}
+
+// message returns the generic message that replaces the message of err.
+func (p *ExposurePolicy) message(err Error) string {
+	if p.Message != "" {
+		return p.Message
+	}
+	if text := http.StatusText(err.GetHTTPCode()); text != "" {
+		return text
+	}
+	return http.StatusText(http.StatusInternalServerError)
+}
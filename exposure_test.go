@@ -0,0 +1,92 @@
+package xerr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func withExposurePolicy(t *testing.T, policy *ExposurePolicy, development bool) {
+	t.Helper()
+	previousPolicy, previousMode := DefaultExposurePolicy, DevelopmentMode
+	DefaultExposurePolicy, DevelopmentMode = policy, development
+	t.Cleanup(func() { DefaultExposurePolicy, DevelopmentMode = previousPolicy, previousMode })
+}
+
+func TestIsServerError(t *testing.T) {
+	tests := []struct {
+		err  Error
+		want bool
+	}{
+		{NewStandardError(INTERNAL, "x"), true},
+		{NewStandardError(UNAVAILABLE, "x"), true},
+		{WrapDefault(errors.New("x")), true},
+		{NewWithHTTPAndGRPC("X", "x", http.StatusBadRequest, 13), true}, // codes.Internal
+		{NewStandardError(NOT_FOUND, "x"), false},
+		{NewStandardError(INVALID_ARGUMENT, "x"), false},
+	}
+	for _, tt := range tests {
+		if got := IsServerError(tt.err); got != tt.want {
+			t.Errorf("%v: expected %t, got %t", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestExposurePolicyHidesMessages(t *testing.T) {
+	// The default policy hides the messages of server errors
+	err := WrapDefault(errors.New(`pq: relation "users" does not exist`)).(*StructuredError)
+
+	rec := httptest.NewRecorder()
+	err.ToHTTP(rec)
+	var httpErr HTTPError
+	if err := json.Unmarshal(rec.Body.Bytes(), &httpErr); err != nil {
+		t.Fatal(err)
+	}
+	if httpErr.Message != "Internal Server Error" {
+		t.Fatalf("expected a generic message, got %q", httpErr.Message)
+	}
+	if httpErr.ID != err.GetOccurrenceID() || httpErr.Code != "UNKNOWN" {
+		t.Fatalf("expected the code and occurrence ID to be kept, got %+v", httpErr)
+	}
+
+	st := err.ToGRPCStatus()
+	if st.Message() != "Internal Server Error" {
+		t.Fatalf("expected a generic gRPC message, got %q", st.Message())
+	}
+	if got := FromGRPCStatus(st).GetOccurrenceID(); got != err.GetOccurrenceID() {
+		t.Fatalf("expected the occurrence ID over gRPC, got %q", got)
+	}
+
+	// Client errors and the error itself are unchanged
+	if got := NewStandardError(NOT_FOUND, "user 42 not found").(*StructuredError).ToGRPCStatus().Message(); got != "user 42 not found" {
+		t.Fatalf("expected client error messages to be sent, got %q", got)
+	}
+	if err.GetMessage() != `pq: relation "users" does not exist` {
+		t.Fatal("expected the message to stay available in the process")
+	}
+}
+
+func TestExposurePolicyMessage(t *testing.T) {
+	withExposurePolicy(t, &ExposurePolicy{Hide: IsServerError, Message: "Something went wrong"}, false)
+	err := NewStandardError(UNAVAILABLE, "redis: connection refused")
+	if got := Join(err, NewStandardError(NOT_FOUND, "missing")).(*MultiError).ToGRPCStatus().Message(); got != "Something went wrong" {
+		t.Fatalf("expected the configured message, got %q", got)
+	}
+
+	withExposurePolicy(t, &ExposurePolicy{Hide: func(Error) bool { return true }}, false)
+	if got := transportMessage(err); got != "Service Unavailable" {
+		t.Fatalf("expected the text of the HTTP status, got %q", got)
+	}
+	if got := transportMessage(NewStandardError(CANCELLED, "cancelled by the client")); got != "Internal Server Error" {
+		t.Fatalf("expected a fallback for statuses without text, got %q", got)
+	}
+}
+
+func TestDevelopmentMode(t *testing.T) {
+	withExposurePolicy(t, DefaultExposurePolicy, true)
+	if got := NewStandardError(INTERNAL, "nil pointer in handler").(*StructuredError).ToGRPCStatus().Message(); got != "nil pointer in handler" {
+		t.Fatalf("expected full messages in development mode, got %q", got)
+	}
+}
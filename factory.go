@@ -0,0 +1,233 @@
+package xerr
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/grpc/status"
+)
+
+// Factory creates errors stamped with the domain and default metadata of a service,
+// so that they don't depend on every call site setting them:
+//
+//	var errs = xerr.NewFactory("users.example.com",
+//		xerr.WithDefaultMetadata("service", "users"),
+//		xerr.WithDefaultMetadata("version", version),
+//	)
+//
+//	var ErrUserNotFound = errs.Define("USER_NOT_FOUND", xerr.WithGRPCStatus(codes.NotFound))
+//
+//	err := errs.Wrap(sqlErr, xerr.INTERNAL)
+//
+// The domain and metadata are only defaults: a domain or metadata key set on the error
+// itself takes precedence. A Factory is safe for concurrent use.
+type Factory struct {
+	domain     string
+	metadata   map[string]Value
+	options    []Option
+	converter  CodeConverter
+	classifier *Classifier
+	registry   *Registry
+}
+
+// FactoryOption configures a Factory.
+type FactoryOption func(*Factory)
+
+// WithDefaultMetadata adds a metadata entry to every error created by the factory,
+// such as the service name, version or region.
+// The value is converted with AnyValue, so numbers, booleans and times keep their type.
+func WithDefaultMetadata(key string, value any) FactoryOption {
+	return func(f *Factory) {
+		f.metadata[key] = AnyValue(value)
+	}
+}
+
+// WithDefaultOptions adds options applied to every error created by the factory,
+// before the options passed to its methods.
+func WithDefaultOptions(opts ...Option) FactoryOption {
+	return func(f *Factory) {
+		f.options = append(f.options, opts...)
+	}
+}
+
+// WithDefaultRedaction sets the RedactionPolicy of every error created by the factory.
+// It is consulted before the DefaultRedactionPolicy, and after the policies set on the error
+// itself with WithRedaction.
+func WithDefaultRedaction(policy *RedactionPolicy) FactoryOption {
+	return WithDefaultOptions(WithRedaction(policy))
+}
+
+// WithConverter sets the CodeConverter used by the factory instead of DefaultConverter.
+func WithConverter(converter CodeConverter) FactoryOption {
+	return func(f *Factory) {
+		f.converter = converter
+	}
+}
+
+// WithClassifier sets the Classifier used by Factory.Wrap and Factory.Wrapf to infer
+// the code of errors wrapped with the UNKNOWN or an empty code, instead of DefaultClassifier.
+func WithClassifier(classifier *Classifier) FactoryOption {
+	return func(f *Factory) {
+		f.classifier = classifier
+	}
+}
+
+// WithRegistry sets the Registry in which Factory.Define registers definitions
+// instead of DefaultRegistry.
+func WithRegistry(registry *Registry) FactoryOption {
+	return func(f *Factory) {
+		f.registry = registry
+	}
+}
+
+// NewFactory creates a Factory for the given ErrorInfo domain.
+func NewFactory(domain string, opts ...FactoryOption) *Factory {
+	f := &Factory{
+		domain:   domain,
+		metadata: make(map[string]Value),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Domain returns the domain of the errors created by the factory.
+func (f *Factory) Domain() string {
+	return f.domain
+}
+
+// Converter returns the CodeConverter of the factory, or DefaultConverter if it has none.
+func (f *Factory) Converter() CodeConverter {
+	if f.converter != nil {
+		return f.converter
+	}
+	return DefaultConverter
+}
+
+// Classifier returns the Classifier of the factory, or DefaultClassifier if it has none.
+func (f *Factory) Classifier() *Classifier {
+	if f.classifier != nil {
+		return f.classifier
+	}
+	return DefaultClassifier
+}
+
+// Registry returns the Registry of the factory, or DefaultRegistry if it has none.
+func (f *Factory) Registry() *Registry {
+	if f.registry != nil {
+		return f.registry
+	}
+	return DefaultRegistry
+}
+
+// New creates an error from a code in the standard code catalog, as NewStandardError does,
+// stamped with the domain, default metadata and default options of the factory.
+func (f *Factory) New(code Code, message string, opts ...Option) Error {
+	return f.stamp(NewStandardError(code, message, f.withDefaults(opts)...).(*StructuredError))
+}
+
+// Wrap wraps err with a code from the standard code catalog, as the Wrap function does,
+// stamped with the domain, default metadata and default options of the factory.
+// When code is UNKNOWN or empty, the code is inferred with the classifier of the factory.
+// It returns nil if err is nil.
+func (f *Factory) Wrap(err error, code Code, opts ...Option) Error {
+	if err == nil {
+		return nil
+	}
+	e := wrapCode(err, f.Classifier().resolve(err, code), err.Error())
+	e.apply(f.withDefaults(opts))
+	return f.stamp(e)
+}
+
+// Wrapf wraps err with a code from the standard code catalog and a formatted message,
+// as the Wrapf function does, stamped with the domain, default metadata and default options
+// of the factory. Options may follow the format arguments; they are applied as for Wrap
+// instead of being formatted:
+//
+//	errs.Wrapf(err, xerr.INTERNAL, "load user %d", id, xerr.WithMetadata("user_id", id))
+//
+// It returns nil if err is nil.
+func (f *Factory) Wrapf(err error, code Code, format string, args ...any) Error {
+	if err == nil {
+		return nil
+	}
+	args, opts := splitOptions(args)
+	e := wrapCode(err, f.Classifier().resolve(err, code), fmt.Sprintf(format, args...))
+	e.apply(f.withDefaults(opts))
+	return f.stamp(e)
+}
+
+// splitOptions separates the options from the format arguments of Wrapf.
+func splitOptions(args []any) ([]any, []Option) {
+	var opts []Option
+	formatArgs := args[:0:0]
+	for _, arg := range args {
+		if opt, ok := arg.(Option); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		formatArgs = append(formatArgs, arg)
+	}
+	return formatArgs, opts
+}
+
+// NewDefinition creates a Definition whose errors are stamped with the domain, default
+// metadata and default options of the factory, without registering it.
+// When the options set only one of the HTTP and gRPC status codes, the other one is derived
+// from it with the converter of the factory, instead of being taken from the code catalog.
+func (f *Factory) NewDefinition(code Code, opts ...Option) *Definition {
+	def := newDefinition(code, f.Converter(), f.withDefaults(opts))
+	f.stamp(def.template)
+	return def
+}
+
+// Define creates a Definition as NewDefinition does and registers it in the registry
+// of the factory. It panics if a definition with the same code is already registered,
+// so it is meant to be used in package-level variable declarations.
+// As the Define function does, it also adds the code to the code catalog.
+func (f *Factory) Define(code Code, opts ...Option) *Definition {
+	def := f.NewDefinition(code, opts...)
+	if err := f.Registry().Register(def); err != nil {
+		panic(err)
+	}
+	def.registerCode()
+	return def
+}
+
+// FromHTTPJSON converts an HTTP JSON error response to an Error, as the FromHTTPJSON
+// function does, deriving gRPC status codes with the converter of the factory.
+// Decoded errors keep the domain and metadata they were sent with.
+func (f *Factory) FromHTTPJSON(jsonBytes []byte, statusCode int) (Error, error) {
+	return fromHTTPJSON(jsonBytes, statusCode, f.Converter())
+}
+
+// FromGRPCStatus converts a gRPC status to an Error, as the FromGRPCStatus function does,
+// deriving HTTP status codes with the converter of the factory.
+// Decoded errors keep the domain and metadata they were sent with.
+func (f *Factory) FromGRPCStatus(st *status.Status) Error {
+	return fromGRPCStatus(st, f.Converter())
+}
+
+// withDefaults returns the default options of the factory followed by opts.
+func (f *Factory) withDefaults(opts []Option) []Option {
+	if len(f.options) == 0 {
+		return opts
+	}
+	return append(slices.Clip(f.options), opts...)
+}
+
+// stamp sets the domain and default metadata of the factory on a newly created error,
+// keeping its own domain and metadata entries.
+func (f *Factory) stamp(e *StructuredError) *StructuredError {
+	if e.Domain == "" {
+		e.Domain = f.domain
+	}
+	if len(f.metadata) > 0 {
+		metadata := copyMetadata(f.metadata)
+		maps.Copy(metadata, typedMetadata(e.Metadata, e.values))
+		e.replaceMetadata(metadata)
+	}
+	return e
+}
@@ -0,0 +1,154 @@
+package xerr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestFactory(opts ...FactoryOption) *Factory {
+	return NewFactory("users.example.com", append([]FactoryOption{
+		WithDefaultMetadata("service", "users"),
+		WithDefaultMetadata("version", 3),
+		WithRegistry(NewRegistry()),
+	}, opts...)...)
+}
+
+func TestFactoryStampsErrors(t *testing.T) {
+	f := newTestFactory()
+	cause := errors.New("connection refused")
+	for name, err := range map[string]Error{
+		"New":    f.New(NOT_FOUND, "user not found"),
+		"Wrap":   f.Wrap(cause, UNAVAILABLE),
+		"Wrapf":  f.Wrapf(cause, UNAVAILABLE, "load user %d", 42),
+		"Define": f.Define("TEST_FACTORY_STAMP").New(),
+	} {
+		se := err.(*StructuredError)
+		if se.Domain != "users.example.com" {
+			t.Errorf("%s: expected the factory domain, got %q", name, se.Domain)
+		}
+		if v, _ := se.GetMetadataValue("version"); v.Kind() != KindInt || v.String() != "3" {
+			t.Errorf("%s: expected typed default metadata, got %v", name, se.GetMetadataValues())
+		}
+		if info := se.GetErrorInfo(); info.Domain != "users.example.com" || info.Metadata["service"] != "users" {
+			t.Errorf("%s: expected the ErrorInfo to carry the domain and metadata, got %v", name, info)
+		}
+	}
+
+	if f.Wrap(nil, INTERNAL) != nil || f.Wrapf(nil, INTERNAL, "ignored") != nil {
+		t.Fatal("expected wrapping nil to return nil")
+	}
+}
+
+func TestFactoryWrapfOptions(t *testing.T) {
+	f := newTestFactory(WithDefaultOptions(WithSeverity(SeverityWarning), WithDocsURL("https://docs.example.com")))
+	err := f.Wrapf(errors.New("connection refused"), UNAVAILABLE, "load user %d", 42,
+		WithSeverity(SeverityCritical), WithMetadata("user_id", 42))
+
+	if err.GetMessage() != "load user 42" {
+		t.Fatalf("expected the options to be left out of the message, got %q", err.GetMessage())
+	}
+	if err.GetSeverity() != SeverityCritical || err.(*StructuredError).GetDocsURL() != "https://docs.example.com" {
+		t.Fatalf("expected the options after the default options, got %s", err.GetSeverity())
+	}
+	if v, _ := err.GetMetadataValue("user_id"); v.Kind() != KindInt {
+		t.Fatalf("expected the metadata option to be applied, got %v", err.GetMetadataValues())
+	}
+}
+
+func TestFactoryDefaultsDoNotOverride(t *testing.T) {
+	f := newTestFactory()
+	def := f.Define("TEST_FACTORY_OVERRIDE", WithDomain("auth.example.com"))
+	err := def.New().WithMetadata("service", "auth")
+	if got := err.(*StructuredError).Domain; got != "auth.example.com" {
+		t.Fatalf("expected the definition domain to win, got %q", got)
+	}
+	if got := err.GetMetadata()["service"]; got != "auth" {
+		t.Fatalf("expected the error metadata to win, got %q", got)
+	}
+
+	// Errors created by the factory don't share their metadata with it
+	e1 := f.New(INTERNAL, "").WithMetadata("version", "4")
+	if got := f.New(INTERNAL, "").GetMetadata()["version"]; got != "3" || e1.GetMetadata()["version"] != "4" {
+		t.Fatalf("expected independent metadata, got %q", got)
+	}
+}
+
+func TestFactoryDefineDerivesStatusCodes(t *testing.T) {
+	f := newTestFactory()
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantHTTP int
+		wantGRPC codes.Code
+	}{
+		{"gRPC only", []Option{WithGRPCStatus(codes.FailedPrecondition)}, http.StatusBadRequest, codes.FailedPrecondition},
+		{"HTTP only", []Option{WithHTTPStatus(http.StatusConflict)}, http.StatusConflict, codes.Aborted},
+		{"both", []Option{WithHTTPStatus(http.StatusTeapot), WithGRPCStatus(codes.Internal)}, http.StatusTeapot, codes.Internal},
+		{"none", nil, http.StatusInternalServerError, codes.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := f.NewDefinition("TEST_FACTORY_DERIVE", tt.opts...)
+			if def.HTTPCode() != tt.wantHTTP || def.GRPCCode() != tt.wantGRPC {
+				t.Fatalf("expected %d/%s, got %d/%s", tt.wantHTTP, tt.wantGRPC, def.HTTPCode(), def.GRPCCode())
+			}
+		})
+	}
+}
+
+func TestFactoryDefineRegisters(t *testing.T) {
+	registry := NewRegistry()
+	f := NewFactory("users.example.com", WithRegistry(registry))
+	def := f.Define("TEST_FACTORY_REGISTERED")
+	if got, ok := registry.Lookup("TEST_FACTORY_REGISTERED"); !ok || got != def {
+		t.Fatal("expected Define to register in the factory registry")
+	}
+	if _, ok := LookupDefinition("TEST_FACTORY_REGISTERED"); ok {
+		t.Fatal("expected Define not to register in the default registry")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Define to panic on a duplicate code")
+		}
+	}()
+	f.Define("TEST_FACTORY_REGISTERED")
+}
+
+// teapotConverter maps every status to 418 and codes.Aborted.
+type teapotConverter struct{}
+
+func (teapotConverter) HTTPToGRPC(int) codes.Code { return codes.Aborted }
+func (teapotConverter) GRPCToHTTP(codes.Code) int { return http.StatusTeapot }
+
+func TestFactoryConverter(t *testing.T) {
+	f := newTestFactory(WithConverter(teapotConverter{}))
+	if def := f.NewDefinition("TEST_FACTORY_CONVERTER", WithGRPCStatus(codes.NotFound)); def.HTTPCode() != http.StatusTeapot {
+		t.Fatalf("expected the factory converter to derive the HTTP status, got %d", def.HTTPCode())
+	}
+
+	decoded, err := f.FromHTTPJSON([]byte(`{"code":"X","message":"m"}`), http.StatusNotFound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.GetGRPCCode() != codes.Aborted {
+		t.Fatalf("expected the factory converter to derive the gRPC code, got %s", decoded.GetGRPCCode())
+	}
+
+	st, _ := status.New(codes.NotFound, "m").WithDetails(&errdetails.ErrorInfo{Reason: "X", Domain: "remote.example.com"})
+	decoded = f.FromGRPCStatus(st)
+	if decoded.GetHTTPCode() != http.StatusTeapot {
+		t.Fatalf("expected the factory converter to derive the HTTP status, got %d", decoded.GetHTTPCode())
+	}
+	if got := decoded.(*StructuredError).Domain; got != "remote.example.com" {
+		t.Fatalf("expected decoded errors to keep their domain, got %q", got)
+	}
+
+	if NewFactory("").Converter() != DefaultConverter {
+		t.Fatal("expected DefaultConverter by default")
+	}
+}
@@ -0,0 +1,84 @@
+package xerr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strconv"
+)
+
+// FingerprintKeys lists the metadata keys whose values take part in Fingerprint.
+// It is empty by default, since metadata usually holds volatile values such as IDs.
+var FingerprintKeys []string
+
+// Fingerprint returns a stable hash of err for grouping and deduplicating occurrences.
+// It is computed from the code, domain and origin (when a stack was captured) of every
+// Error in the cause chain, including every branch of joined errors, and from the
+// values of the metadata keys listed in FingerprintKeys. Messages, other metadata,
+// occurrence IDs and timestamps are ignored.
+// It returns an empty string if err is nil.
+func Fingerprint(err error) string {
+	return FingerprintWithKeys(err, FingerprintKeys...)
+}
+
+// FingerprintWithKeys is like Fingerprint, but includes the values of the given metadata keys
+// instead of those listed in FingerprintKeys.
+func FingerprintWithKeys(err error, keys ...string) string {
+	if err == nil {
+		return ""
+	}
+	f := fingerprinter{h: sha256.New(), keys: keys}
+	f.chain(err)
+	return hex.EncodeToString(f.h.Sum(nil)[:16])
+}
+
+// fingerprinter feeds the stable attributes of a cause chain into a hash.
+type fingerprinter struct {
+	h    hash.Hash
+	keys []string
+}
+
+// chain adds every layer of the cause chain of err.
+func (f *fingerprinter) chain(err error) {
+	for err != nil {
+		if xe, ok := err.(Error); ok {
+			f.layer(xe)
+		}
+		if errs, ok := branches(err); ok {
+			f.write("join", strconv.Itoa(len(errs)))
+			for _, branch := range errs {
+				f.write("branch")
+				f.chain(branch)
+			}
+			return
+		}
+		err = errors.Unwrap(err)
+	}
+}
+
+// layer adds the stable attributes of a single Error.
+func (f *fingerprinter) layer(err Error) {
+	f.write("code", err.GetCode())
+	if se, ok := err.(*StructuredError); ok {
+		f.write("domain", se.Domain)
+		if origin, ok := se.Origin(); ok {
+			f.write("origin", origin.Function()+":"+strconv.Itoa(origin.Line()))
+		}
+	}
+	for _, key := range f.keys {
+		if v, ok := err.GetMetadataValue(key); ok {
+			f.write("metadata", key, v.String())
+		}
+	}
+}
+
+// write adds length-prefixed fields to the hash, so that different fields never collide.
+func (f *fingerprinter) write(fields ...string) {
+	for _, field := range fields {
+		f.h.Write([]byte(strconv.Itoa(len(field))))
+		f.h.Write([]byte{':'})
+		f.h.Write([]byte(field))
+	}
+	f.h.Write([]byte{'\n'})
+}
@@ -0,0 +1,74 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFingerprintIgnoresVolatileData(t *testing.T) {
+	a := Wrap(NewStandardError(NOT_FOUND, "user 1 not found").WithMetadata("user_id", "1"), INTERNAL)
+	b := Wrap(NewStandardError(NOT_FOUND, "user 2 not found").WithMetadata("user_id", "2"), INTERNAL)
+
+	if Fingerprint(a) == "" || Fingerprint(a) != Fingerprint(b) {
+		t.Fatalf("expected equal fingerprints, got %s and %s", Fingerprint(a), Fingerprint(b))
+	}
+	if Fingerprint(fmt.Errorf("request 7: %w", a)) != Fingerprint(a) {
+		t.Fatal("expected plain wrapping layers to be ignored")
+	}
+	if Fingerprint(nil) != "" {
+		t.Fatal("expected an empty fingerprint for nil")
+	}
+}
+
+func TestFingerprintDistinguishes(t *testing.T) {
+	base := NewStandardError(NOT_FOUND, "user not found")
+	tests := map[string]Error{
+		"code":   NewStandardError(ALREADY_EXISTS, "user not found"),
+		"chain":  Wrap(base, INTERNAL),
+		"domain": base.(*StructuredError).WithErrorInfo("users.example.com", nil),
+		"join":   Join(base, errors.New("plain")),
+	}
+	for name, err := range tests {
+		if Fingerprint(err) == Fingerprint(base) {
+			t.Errorf("%s: expected a different fingerprint", name)
+		}
+	}
+}
+
+func TestFingerprintOrigin(t *testing.T) {
+	withStackMode(t, StackCaller)
+
+	newErr := func() Error { return NewStandardError(NOT_FOUND, "user not found") }
+	first, again := newErr(), newErr()
+	elsewhere := NewStandardError(NOT_FOUND, "user not found")
+
+	if Fingerprint(first) != Fingerprint(again) {
+		t.Fatal("expected errors from the same origin to share a fingerprint")
+	}
+	if Fingerprint(first) == Fingerprint(elsewhere) {
+		t.Fatal("expected errors from different origins to differ")
+	}
+}
+
+func TestFingerprintKeys(t *testing.T) {
+	a := NewStandardError(NOT_FOUND, "").WithMetadata("table", "users").WithMetadata("id", "1")
+	b := NewStandardError(NOT_FOUND, "").WithMetadata("table", "orders").WithMetadata("id", "2")
+
+	if Fingerprint(a) != Fingerprint(b) {
+		t.Fatal("expected metadata to be ignored by default")
+	}
+	if FingerprintWithKeys(a, "table") == FingerprintWithKeys(b, "table") {
+		t.Fatal("expected the configured key to take part")
+	}
+
+	previous := FingerprintKeys
+	FingerprintKeys = []string{"table"}
+	defer func() { FingerprintKeys = previous }()
+	if Fingerprint(a) == Fingerprint(b) {
+		t.Fatal("expected FingerprintKeys to take part")
+	}
+	if Fingerprint(a) != Fingerprint(a.WithMetadata("id", "3")) {
+		t.Fatal("expected keys not listed to be ignored")
+	}
+}
@@ -0,0 +1,152 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format implements fmt.Formatter.
+//
+//	%s, %v  compact form, equivalent to Error()
+//	%q      quoted compact form
+//	%+v     multi-line tree of every layer in the cause chain, including codes,
+//	        statuses, metadata, details and the captured stack of each layer
+func (e *StructuredError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// Format implements fmt.Formatter.
+// It supports the same verbs as StructuredError.Format; %+v renders every aggregated error.
+func (m *MultiError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, m)
+}
+
+// formatError formats err according to the fmt.Formatter interface.
+func formatError(s fmt.State, verb rune, err error) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeErrorTree(s, err)
+			return
+		}
+		_, _ = io.WriteString(s, err.Error())
+	case 's':
+		_, _ = io.WriteString(s, err.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", err.Error())
+	}
+}
+
+// treeWriter writes indented lines of an error tree.
+type treeWriter struct {
+	w     io.Writer
+	lines int
+}
+
+// line writes a single line with the given indentation.
+// Embedded newlines are indented to the same level.
+func (t *treeWriter) line(indent string, text string) {
+	if t.lines > 0 {
+		_, _ = io.WriteString(t.w, "\n")
+	}
+	t.lines++
+	text = strings.ReplaceAll(text, "\n", "\n"+indent)
+	_, _ = io.WriteString(t.w, indent+text)
+}
+
+// writeErrorTree renders err and its cause chain as a multi-line tree.
+func writeErrorTree(w io.Writer, err error) {
+	t := &treeWriter{w: w}
+	t.chain(err, "", "")
+}
+
+// chain renders err and every error it wraps.
+// The first layer is prefixed with label; later layers are prefixed with "caused by: ".
+func (t *treeWriter) chain(err error, indent string, label string) {
+	for err != nil {
+		if errs, ok := branches(err); ok {
+			header := err.Error()
+			if strings.Contains(header, "\n") {
+				header = strconv.Itoa(len(errs)) + " joined errors"
+			}
+			t.line(indent, label+header)
+			for i, branch := range errs {
+				t.chain(branch, indent+"    ", "["+strconv.Itoa(i)+"] ")
+			}
+			return
+		}
+		switch x := err.(type) {
+		case *StructuredError:
+			t.line(indent, label+x.Error())
+			t.fields(indent+"    ", x)
+			err = x.Cause
+		default:
+			t.line(indent, label+err.Error())
+			err = errors.Unwrap(err)
+		}
+		label = "caused by: "
+	}
+}
+
+// fields renders the attributes of a single StructuredError layer.
+func (t *treeWriter) fields(indent string, e *StructuredError) {
+	t.line(indent, "code: "+e.GetCode())
+	t.line(indent, "http: "+strconv.Itoa(e.HTTPCode))
+	t.line(indent, "grpc: "+e.GRPCCode.String())
+	t.line(indent, "severity: "+e.GetSeverity().String())
+	if retry := e.GetRetryability(); retry.retryable() {
+		if delay := e.GetRetryDelay(); delay > 0 {
+			t.line(indent, "retry: after "+delay.String())
+		} else {
+			t.line(indent, "retry: "+retry.String())
+		}
+	}
+	if e.Domain != "" {
+		t.line(indent, "domain: "+e.Domain)
+	}
+	if e.docsURL != "" {
+		t.line(indent, "docs: "+e.docsURL)
+	}
+	if reason := e.GetUserReason(); reason != "" {
+		t.line(indent, "reason: "+reason)
+	}
+
+	var metadata, details []string
+	metadataValues := loggedMetadata(e, e.allMetadata())
+	for _, k := range sortedKeys(metadataValues) {
+		v := metadataValues[k].String()
+		switch {
+		case strings.HasPrefix(k, "field:"):
+			details = append(details, "bad request: "+strings.TrimPrefix(k, "field:")+": "+v)
+		case strings.HasPrefix(k, "precondition:"):
+			details = append(details, "precondition failure: "+strings.TrimPrefix(k, "precondition:")+": "+v)
+		default:
+			metadata = append(metadata, k+": "+v)
+		}
+	}
+	t.list(indent, "metadata:", metadata)
+	t.list(indent, "details:", details)
+
+	if len(e.stack) > 0 {
+		t.line(indent, "stack:")
+		for _, f := range e.stack {
+			function, file, line := f.location()
+			t.line(indent+"    ", function)
+			t.line(indent+"        ", file+":"+strconv.Itoa(line))
+		}
+	}
+}
+
+// list renders a list of entries under a title, if there are any.
+func (t *treeWriter) list(indent string, title string, entries []string) {
+	if len(entries) == 0 {
+		return
+	}
+	t.line(indent, title)
+	for _, entry := range entries {
+		t.line(indent+"    ", entry)
+	}
+}
@@ -0,0 +1,86 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatCompact(t *testing.T) {
+	err := New("NOT_FOUND", "user not found").WithMetadata("user_id", "42")
+
+	for _, format := range []string{"%s", "%v"} {
+		if got := fmt.Sprintf(format, err); got != "[NOT_FOUND] user not found" {
+			t.Errorf("%s: unexpected output %q", format, got)
+		}
+	}
+	if got := fmt.Sprintf("%q", err); got != `"[NOT_FOUND] user not found"` {
+		t.Errorf("%%q: unexpected output %s", got)
+	}
+}
+
+func TestFormatVerboseTree(t *testing.T) {
+	root := errors.New("connection refused")
+	inner := Wrap(root, UNAVAILABLE).WithMetadata("host", "db-1")
+	outer := Wrapf(fmt.Errorf("load user: %w", inner), NOT_FOUND, "user %d not found", 42).
+		WithReason("We could not find your account").(*StructuredError)
+	outer = outer.WithBadRequest(map[string]string{"id": "unknown id"}).(*StructuredError)
+
+	want := strings.Join([]string{
+		"[NOT_FOUND] user 42 not found",
+		"    code: NOT_FOUND",
+		"    http: 404",
+		"    grpc: NotFound",
+		"    severity: info",
+		"    reason: We could not find your account",
+		"    details:",
+		"        bad request: id: unknown id",
+		"caused by: load user: [UNAVAILABLE] connection refused",
+		"caused by: [UNAVAILABLE] connection refused",
+		"    code: UNAVAILABLE",
+		"    http: 503",
+		"    grpc: Unavailable",
+		"    severity: error",
+		"    retry: retryable",
+		"    metadata:",
+		"        host: db-1",
+		"caused by: connection refused",
+	}, "\n")
+	if got := fmt.Sprintf("%+v", outer); got != want {
+		t.Fatalf("unexpected tree:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatVerboseJoinedCauses(t *testing.T) {
+	joined := errors.Join(New("A", "first"), errors.New("second"))
+	err := Wrapf(joined, ABORTED, "batch failed")
+
+	want := strings.Join([]string{
+		"[ABORTED] batch failed",
+		"    code: ABORTED",
+		"    http: 409",
+		"    grpc: Aborted",
+		"    severity: info",
+		"    retry: retryable",
+		"caused by: 2 joined errors",
+		"    [0] [A] first",
+		"        code: A",
+		"        http: 500",
+		"        grpc: Unknown",
+		"        severity: error",
+		"    [1] second",
+	}, "\n")
+	if got := fmt.Sprintf("%+v", err); got != want {
+		t.Fatalf("unexpected tree:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatVerboseStack(t *testing.T) {
+	err := New("CODE", "msg").(*StructuredError).WithCaller()
+	got := fmt.Sprintf("%+v", err)
+	if !strings.Contains(got, "    stack:\n        github.com/nduyhai/xerr.TestFormatVerboseStack\n            ") ||
+		!strings.Contains(got, "format_test.go:") {
+		t.Fatalf("expected stack in tree, got:\n%s", got)
+	}
+}
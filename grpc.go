@@ -1,56 +1,107 @@
 package xerr
 
 import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	_ "google.golang.org/grpc/codes" // Used for GRPCCode field type (codes.Code)
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // ToGRPCStatus converts a StructuredError to a gRPC status.Status.
 // It includes error details if available.
+// See UnaryServerInterceptor to also log the error with the response logger.
 func (e *StructuredError) ToGRPCStatus() *status.Status {
-	st := status.New(e.GRPCCode, e.GetMessage())
+	st := status.New(e.GRPCCode, transportMessage(e))
 
-	// If we have additional details, add them to the status
-	if len(e.Metadata) > 0 || e.Domain != "" {
-		domain := e.Domain
-		if domain == "" {
-			domain = "github.com/nduyhai/xerr"
-		}
-		errorInfo := &errdetails.ErrorInfo{
-			Reason:   e.GetCode(),
-			Domain:   domain,
-			Metadata: e.Metadata,
-		}
+	var details []protoadapt.MessageV1
 
-		// Add ErrorInfo with metadata
-		var err error
-		st, err = st.WithDetails(errorInfo)
-		if err != nil {
-			// If we can't add details, just return the status without details
-			return st
-		}
+	// If we have additional details, add ErrorInfo with metadata
+	metadata := publicMetadata(e, e.allMetadata())
+	if info := errorInfoOf(e); len(info.Metadata) > 0 || e.Domain != "" {
+		details = append(details, info)
+	}
+
+	// Add the retry delay as RetryInfo
+	if retryInfo := retryInfoOf(e); retryInfo != nil {
+		details = append(details, retryInfo)
+	}
+
+	// Add the documentation link as Help
+	if help := e.GetHelp(); help != nil {
+		details = append(details, help)
+	}
+
+	// ErrorInfo only carries strings, so typed metadata is also sent as a google.protobuf.Struct
+	if hasTypedMetadata(metadata) {
+		details = append(details, metadataToStruct(metadata))
 	}
 
 	// Add localized message if available
 	userReason := e.GetUserReason()
 	if userReason != "" {
-		localizedMsg := &errdetails.LocalizedMessage{
-			Locale:  "en-US",
+		details = append(details, &errdetails.LocalizedMessage{
+			Locale:  defaultLocale,
 			Message: userReason,
-		}
+		})
+	}
 
-		// Add localized message
-		st, _ = st.WithDetails(localizedMsg)
+	// Add a localized message for every translation of the reason, except one for the
+	// default locale when the user reason is already sent under it
+	if l, ok := e.reason.(Localizer); ok {
+		locales := l.Locales()
+		sort.Strings(locales)
+		for _, locale := range locales {
+			if locale == defaultLocale && userReason != "" {
+				continue
+			}
+			details = append(details, &errdetails.LocalizedMessage{
+				Locale:  locale,
+				Message: l.Localize(locale),
+			})
+		}
 	}
 
-	return st
+	return withDetails(st, details...)
+}
+
+// defaultLocale is the locale of the LocalizedMessage carrying the user reason of an error.
+const defaultLocale = "en-US"
+
+// withDetails adds the details to the status.
+// If the details can't be added, the status is returned without them.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	if len(details) == 0 {
+		return st
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return withDetails
 }
 
 // FromGRPCStatus converts a gRPC status.Status to an Error.
 // It extracts error details if available and returns an Error interface
 // that can be used with all the methods defined in the interface.
+// Typed metadata is restored from a google.protobuf.Struct detail when present.
+// The LocalizedMessage for "en-US" becomes the user reason, and those for other locales
+// its translations, see StructuredError.LocalizedReason.
+// A status carrying more than one ErrorInfo, such as one produced by
+// MultiError.ToGRPCStatus, is converted to a MultiError.
 func FromGRPCStatus(st *status.Status) Error {
+	return fromGRPCStatus(st, DefaultConverter)
+}
+
+// fromGRPCStatus implements FromGRPCStatus, deriving HTTP status codes with converter.
+func fromGRPCStatus(st *status.Status, converter CodeConverter) Error {
 	if st == nil {
 		return nil
 	}
@@ -59,40 +110,211 @@ func FromGRPCStatus(st *status.Status) Error {
 	code := "UNKNOWN"
 	message := st.Message()
 	userReason := ""
+	var translations map[string]string
 	domain := ""
-	metadata := make(map[string]string)
+	metadata := make(map[string]Value)
+	var typed *structpb.Struct
+	var infos []*errdetails.ErrorInfo
+	var reserved reservedMetadata
+	var retryDelay time.Duration
+	docsURL := ""
 
 	// Extract details from the status
 	for _, detail := range st.Details() {
 		switch d := detail.(type) {
 		case *errdetails.ErrorInfo:
+			infos = append(infos, d)
+			if len(infos) > 1 {
+				continue
+			}
+
 			// Use the reason as the error code
 			code = d.Reason
 			domain = d.Domain
 
-			// Copy metadata
-			for k, v := range d.Metadata {
-				metadata[k] = v
+			// Copy metadata, except for the reserved keys
+			var md map[string]string
+			md, reserved = splitReservedMetadata(d.Metadata)
+			for k, v := range md {
+				metadata[k] = StringValue(v)
+			}
+
+		case *errdetails.RetryInfo:
+			retryDelay = max(d.GetRetryDelay().AsDuration(), 0)
+
+		case *errdetails.Help:
+			// Use the first link as the documentation link
+			if links := d.GetLinks(); len(links) > 0 && docsURL == "" {
+				docsURL = links[0].GetUrl()
 			}
 
+		case *structpb.Struct:
+			typed = d
+
 		case *errdetails.LocalizedMessage:
-			// Use the localized message as the user reason
-			userReason = d.Message
+			// Use the message of the default locale as the user reason, and the others as translations
+			if d.Locale == defaultLocale {
+				if userReason == "" {
+					userReason = d.Message
+				}
+			} else if _, ok := translations[d.Locale]; !ok && d.Locale != "" {
+				if translations == nil {
+					translations = make(map[string]string)
+				}
+				translations[d.Locale] = d.Message
+			}
+		}
+	}
+
+	if len(infos) > 1 {
+		return multiFromGRPCStatus(st, infos, typed, userReason, retryDelay, converter)
+	}
+
+	// Typed values take precedence over their string encoding in ErrorInfo, except for
+	// numbers that differ from it, such as an integer that lost precision as a double
+	for k, v := range metadataFromStruct(typed) {
+		if s, ok := metadata[k]; ok && v.kind == KindFloat {
+			v = typedValue(s.String(), v)
 		}
+		metadata[k] = v
 	}
 
 	// Create the error with the extracted information
-	reason := NewDefaultReason(code, message)
-	if userReason != "" {
-		reason.WithReason(userReason)
+	var reason Reason = NewDefaultReason(code, message).WithReason(userReason)
+	if len(translations) > 0 {
+		reason = localizedReason(code, message, userReason, translations)
+	}
+
+	e := &StructuredError{
+		reason:     reason,
+		GRPCCode:   st.Code(),
+		HTTPCode:   converter.GRPCToHTTP(st.Code()),
+		Domain:     domain,
+		retry:      decodeRetry(reserved.retryable, retryDelay),
+		retryDelay: retryDelay,
+		occurrence: decodedOccurrence(reserved.occurrenceID, reserved.timestamp),
+		docsURL:    docsURL,
+	}
+	e.replaceMetadata(metadata)
+	return e
+}
+
+// localizedReason returns a TemplateReason that localizes the received user reason with the
+// received translations. The texts are escaped, since they are already rendered.
+func localizedReason(code, message, userReason string, translations map[string]string) *TemplateReason {
+	escape := strings.NewReplacer("{", "{{", "}", "}}").Replace
+	reason := NewTemplateReason(code, escape(message), nil).WithReason(escape(userReason))
+	for locale, translation := range translations {
+		reason = reason.WithTranslation(locale, escape(translation))
+	}
+	return reason
+}
+
+// reservedMetadata holds the values carried under the reserved ErrorInfo metadata keys.
+type reservedMetadata struct {
+	retryable    *bool
+	occurrenceID string
+	timestamp    time.Time
+}
+
+// splitReservedMetadata separates the reserved keys from ErrorInfo metadata.
+// Invalid reserved values are ignored.
+func splitReservedMetadata(md map[string]string) (map[string]string, reservedMetadata) {
+	var reserved reservedMetadata
+	metadata := make(map[string]string, len(md))
+	for k, v := range md {
+		switch k {
+		case retryableKey:
+			if b, err := strconv.ParseBool(v); err == nil {
+				reserved.retryable = &b
+			}
+		case occurrenceIDKey:
+			reserved.occurrenceID = v
+		case timestampKey:
+			reserved.timestamp, _ = time.Parse(time.RFC3339Nano, v)
+		default:
+			metadata[k] = v
+		}
+	}
+	return metadata, reserved
+}
+
+// multiFromGRPCStatus rebuilds a MultiError from a status carrying several ErrorInfo details.
+// The first ErrorInfo belongs to the representative error, whose code is kept together with
+// the status code and message. The other aggregated errors take their status codes and
+// messages from the standard code catalog. A retry delay and the metadata in typed apply
+// to the aggregate.
+func multiFromGRPCStatus(st *status.Status, infos []*errdetails.ErrorInfo, typed *structpb.Struct, userReason string, retryDelay time.Duration, converter CodeConverter) Error {
+	m := &MultiError{precedence: PrecedenceFirst}
+	for _, info := range infos {
+		code := Code(info.Reason)
+		md, reserved := splitReservedMetadata(info.Metadata)
+		m.errs = append(m.errs, &StructuredError{
+			reason:     NewDefaultReason(info.Reason, code.DefaultMessage()),
+			GRPCCode:   code.GRPCCode(),
+			HTTPCode:   code.HTTPCode(),
+			Metadata:   copyStringMetadata(md),
+			Domain:     info.Domain,
+			retry:      decodeRetry(reserved.retryable, 0),
+			occurrence: decodedOccurrence(reserved.occurrenceID, reserved.timestamp),
+		})
+	}
+	if retryDelay > 0 {
+		m.retry, m.retryDelay = RetryableAfterDelay, retryDelay
+	}
+
+	grpcCode := st.Code()
+	m.grpcCode = &grpcCode
+	m.httpCode = converter.GRPCToHTTP(grpcCode)
+	m.reason = NewDefaultReason(infos[0].Reason, st.Message()).WithReason(userReason)
+	if metadata := metadataFromStruct(typed); len(metadata) > 0 {
+		m.metadata = metadata
+	}
+	return m
+}
+
+// toGRPCStatus converts any Error to a gRPC status.
+func toGRPCStatus(err Error) *status.Status {
+	switch x := err.(type) {
+	case *StructuredError:
+		return x.ToGRPCStatus()
+	case *MultiError:
+		return x.ToGRPCStatus()
+	default:
+		return status.New(err.GetGRPCCode(), transportMessage(err))
+	}
+}
+
+// UnaryServerInterceptor returns a gRPC server interceptor that converts the errors returned
+// by handlers to a status with ToGRPCStatus, after logging them with the response logger.
+// Errors that are not an Error are wrapped as by Join, except gRPC status errors, which are
+// returned unchanged.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, serverError(ctx, err)
 	}
+}
 
-	return &StructuredError{
-		reason:   reason,
-		GRPCCode: st.Code(),
-		HTTPCode: DefaultConverter.GRPCToHTTP(st.Code()),
-		Metadata: metadata,
-		Domain:   domain,
+// StreamServerInterceptor returns a gRPC server interceptor that converts the errors returned
+// by stream handlers like UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return serverError(ss.Context(), handler(srv, ss))
 	}
 }
 
+// serverError converts an error returned by a gRPC handler to a status error, logging it.
+func serverError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := First(err); !ok {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+	}
+	xe := asError(err)
+	LogResponse(ctx, xe)
+	return toGRPCStatus(xe).Err()
+}
@@ -1,76 +1,300 @@
 package xerr
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // HTTPError represents the JSON structure for HTTP error responses.
+//
+// Retryable is only set when the retry classification of the error differs from the one
+// derived from the HTTP status code, or when RetryDelay is set.
 type HTTPError struct {
-	Code     string            `json:"code"`               // Machine-readable error code
-	Message  string            `json:"message"`            // Developer-facing error message
-	Reason   string            `json:"reason,omitempty"`   // User-facing error message
-	Metadata map[string]string `json:"metadata,omitempty"` // Additional error context
+	Code       string            `json:"code"`                  // Machine-readable error code
+	Message    string            `json:"message"`               // Developer-facing error message
+	Reason     string            `json:"reason,omitempty"`      // User-facing error message
+	Metadata   map[string]string `json:"metadata,omitempty"`    // Additional error context, in its string encoding
+	Retryable  *bool             `json:"retryable,omitempty"`   // Whether the request can be retried
+	RetryDelay string            `json:"retry_delay,omitempty"` // Delay before retrying, such as "1.5s"
+	ID         string            `json:"id,omitempty"`          // Unique ID of the error instance
+	DocsURL    string            `json:"docs_url,omitempty"`    // Link to the documentation of the error
+	Timestamp  time.Time         `json:"timestamp,omitzero"`    // Creation time of the error instance
+	Status     int               `json:"status,omitempty"`      // HTTP status code of an aggregated error
+	Errors     []HTTPError       `json:"errors,omitempty"`      // Aggregated errors of a MultiError
+
+	// MetadataValues holds the typed values of the Metadata entries that are not strings.
+	// They are sent in their native JSON types, and restored when decoding, except that
+	// timestamps and non-finite floats decode as strings and integral floats as integers,
+	// see Value.MarshalJSON.
+	MetadataValues map[string]Value `json:"-"`
 }
 
-// ToHTTP converts a StructuredError to an HTTP response.
-// It writes the error as JSON to the http.ResponseWriter with the appropriate status code.
-func (e *StructuredError) ToHTTP(w http.ResponseWriter) {
+// httpErrorJSON is the JSON encoding of HTTPError, with the metadata in native JSON types.
+type httpErrorJSON struct {
+	Code       string           `json:"code"`
+	Message    string           `json:"message"`
+	Reason     string           `json:"reason,omitempty"`
+	Metadata   map[string]Value `json:"metadata,omitempty"`
+	Retryable  *bool            `json:"retryable,omitempty"`
+	RetryDelay string           `json:"retry_delay,omitempty"`
+	ID         string           `json:"id,omitempty"`
+	DocsURL    string           `json:"docs_url,omitempty"`
+	Timestamp  time.Time        `json:"timestamp,omitzero"`
+	Status     int              `json:"status,omitempty"`
+	Errors     []HTTPError      `json:"errors,omitempty"`
+}
+
+// MarshalJSON encodes the response, with the metadata in native JSON types.
+func (h HTTPError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(httpErrorJSON{
+		Code:       h.Code,
+		Message:    h.Message,
+		Reason:     h.Reason,
+		Metadata:   h.metadataValues(),
+		Retryable:  h.Retryable,
+		RetryDelay: h.RetryDelay,
+		ID:         h.ID,
+		DocsURL:    h.DocsURL,
+		Timestamp:  h.Timestamp,
+		Status:     h.Status,
+		Errors:     h.Errors,
+	})
+}
+
+// UnmarshalJSON decodes a response, keeping the typed values of the metadata in MetadataValues.
+func (h *HTTPError) UnmarshalJSON(data []byte) error {
+	var aux httpErrorJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*h = HTTPError{
+		Code:       aux.Code,
+		Message:    aux.Message,
+		Reason:     aux.Reason,
+		Retryable:  aux.Retryable,
+		RetryDelay: aux.RetryDelay,
+		ID:         aux.ID,
+		DocsURL:    aux.DocsURL,
+		Timestamp:  aux.Timestamp,
+		Status:     aux.Status,
+		Errors:     aux.Errors,
+	}
+	h.setMetadata(aux.Metadata)
+	return nil
+}
+
+// setMetadata sets Metadata and MetadataValues from typed metadata.
+func (h *HTTPError) setMetadata(metadata map[string]Value) {
+	h.Metadata, h.MetadataValues = stringMetadata(metadata), nil
+	if hasTypedMetadata(metadata) {
+		h.MetadataValues = metadata
+	}
+}
+
+// metadataValues returns the typed metadata of the response.
+func (h HTTPError) metadataValues() map[string]Value {
+	return typedMetadata(h.Metadata, h.MetadataValues)
+}
+
+// httpError builds the HTTPError response body for the error.
+func (e *StructuredError) httpError() HTTPError {
+	httpErr := HTTPError{
+		Code:    e.GetCode(),
+		Message: transportMessage(e),
+		Reason:  e.GetUserReason(),
+		DocsURL: e.docsURL,
+	}
+	httpErr.setMetadata(publicMetadata(e, e.allMetadata()))
+	httpErr.annotate(e)
+	return httpErr
+}
+
+// annotate sets the occurrence of err and its retry hint, as seen by a receiver that
+// derives the retry classification from the HTTP status code.
+func (h *HTTPError) annotate(err Error) {
+	retryable, delay := encodeRetry(err, DefaultConverter.HTTPToGRPC(err.GetHTTPCode()))
+	h.Retryable = retryable
+	if delay > 0 {
+		h.RetryDelay = delay.String()
+	}
+	h.ID = err.GetOccurrenceID()
+	h.Timestamp = err.GetTimestamp()
+}
+
+// retry returns the retry classification and delay of the response.
+// An unparseable delay is ignored.
+func (h HTTPError) retry() (Retryability, time.Duration) {
+	delay, _ := time.ParseDuration(h.RetryDelay)
+	if delay < 0 {
+		delay = 0
+	}
+	return decodeRetry(h.Retryable, delay), delay
+}
+
+// toHTTPError builds the HTTPError response body for any Error.
+func toHTTPError(err Error) HTTPError {
+	switch x := err.(type) {
+	case *StructuredError:
+		return x.httpError()
+	case *MultiError:
+		return x.httpError()
+	default:
+		httpErr := HTTPError{
+			Code:    err.GetCode(),
+			Message: transportMessage(err),
+			Reason:  err.GetUserReason(),
+		}
+		httpErr.setMetadata(publicMetadata(err, err.GetMetadataValues()))
+		httpErr.annotate(err)
+		return httpErr
+	}
+}
+
+// writeHTTP writes the HTTPError as JSON with the given status code.
+// A retry delay is also sent in the Retry-After header, in whole seconds.
+func writeHTTP(w http.ResponseWriter, statusCode int, httpErr HTTPError) {
 	// Set content type
 	w.Header().Set("Content-Type", "application/json")
 
+	if _, delay := httpErr.retry(); delay > 0 {
+		seconds := int64((delay + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+
 	// Set status code
-	w.WriteHeader(e.HTTPCode)
+	w.WriteHeader(statusCode)
 
-	// Create HTTP error response
-	httpErr := HTTPError{
-		Code:     e.GetCode(),
-		Message:  e.GetMessage(),
-		Reason:   e.GetUserReason(),
-		Metadata: e.Metadata,
+	// Write JSON response
+	_, _ = w.Write(append(encodeHTTP(httpErr), '\n'))
+}
+
+// marshalHTTP encodes the HTTPError as JSON and returns it with the given status code.
+func marshalHTTP(statusCode int, httpErr HTTPError) ([]byte, int) {
+	return encodeHTTP(httpErr), statusCode
+}
+
+// encodeHTTP encodes the HTTPError as JSON.
+// Only the metadata holds caller-provided values. Should they fail to encode, the response
+// is encoded again without its metadata and with the encoding error in its message,
+// so that the client still receives the error code instead of an empty body.
+func encodeHTTP(httpErr HTTPError) []byte {
+	jsonBytes, err := json.Marshal(httpErr)
+	if err == nil {
+		return jsonBytes
 	}
+	httpErr = withoutMetadata(httpErr)
+	httpErr.Message = fmt.Sprintf("%s (metadata dropped: %v)", httpErr.Message, err)
+	jsonBytes, _ = json.Marshal(httpErr)
+	return jsonBytes
+}
 
-	// Write JSON response
-	_ = json.NewEncoder(w).Encode(httpErr)
+// withoutMetadata returns a copy of the HTTPError and its aggregated errors without metadata.
+func withoutMetadata(httpErr HTTPError) HTTPError {
+	httpErr.Metadata = nil
+	httpErr.MetadataValues = nil
+	if httpErr.Errors != nil {
+		errs := make([]HTTPError, len(httpErr.Errors))
+		for i, child := range httpErr.Errors {
+			errs[i] = withoutMetadata(child)
+		}
+		httpErr.Errors = errs
+	}
+	return httpErr
+}
+
+// ToHTTP converts a StructuredError to an HTTP response.
+// It writes the error as JSON to the http.ResponseWriter with the appropriate status code.
+// See WriteError to also log the error with the response logger.
+func (e *StructuredError) ToHTTP(w http.ResponseWriter) {
+	writeHTTP(w, e.HTTPCode, e.httpError())
 }
 
 // ToHTTPJSON converts a StructuredError to an HTTP JSON error response.
 // It returns the JSON bytes and the HTTP status code.
 func (e *StructuredError) ToHTTPJSON() ([]byte, int) {
-	httpErr := HTTPError{
-		Code:     e.GetCode(),
-		Message:  e.GetMessage(),
-		Reason:   e.GetUserReason(),
-		Metadata: e.Metadata,
-	}
-
-	jsonBytes, _ := json.Marshal(httpErr)
-	return jsonBytes, e.HTTPCode
+	return marshalHTTP(e.HTTPCode, e.httpError())
 }
 
 // FromHTTPJSON converts an HTTP JSON error response to an Error.
 // It returns an Error interface that can be used with all the methods defined in the interface.
+// A response listing aggregated errors, such as one produced by MultiError.ToHTTP,
+// is converted to a MultiError.
 func FromHTTPJSON(jsonBytes []byte, statusCode int) (Error, error) {
+	return fromHTTPJSON(jsonBytes, statusCode, DefaultConverter)
+}
+
+// fromHTTPJSON implements FromHTTPJSON, deriving gRPC codes with converter.
+func fromHTTPJSON(jsonBytes []byte, statusCode int, converter CodeConverter) (Error, error) {
 	var httpErr HTTPError
 	if err := json.Unmarshal(jsonBytes, &httpErr); err != nil {
 		return nil, err
 	}
 
+	if len(httpErr.Errors) > 0 {
+		return multiFromHTTPError(httpErr, statusCode, converter), nil
+	}
+	return fromHTTPError(httpErr, statusCode, converter), nil
+}
+
+// fromHTTPError converts a decoded HTTPError to a StructuredError.
+func fromHTTPError(httpErr HTTPError, statusCode int, converter CodeConverter) *StructuredError {
 	// Create a DefaultReason with the code and message
 	reason := NewDefaultReason(httpErr.Code, httpErr.Message)
 	if httpErr.Reason != "" {
-		reason.WithReason(httpErr.Reason)
+		reason = reason.WithReason(httpErr.Reason)
 	}
 
-	return &StructuredError{
-		reason:   reason,
-		GRPCCode: DefaultConverter.HTTPToGRPC(statusCode),
-		HTTPCode: statusCode,
-		Metadata: httpErr.Metadata,
-	}, nil
+	retry, delay := httpErr.retry()
+	e := &StructuredError{
+		reason:     reason,
+		GRPCCode:   converter.HTTPToGRPC(statusCode),
+		HTTPCode:   statusCode,
+		retry:      retry,
+		retryDelay: delay,
+		occurrence: decodedOccurrence(httpErr.ID, httpErr.Timestamp),
+		docsURL:    httpErr.DocsURL,
+	}
+	e.replaceMetadata(httpErr.metadataValues())
+	return e
 }
 
+// multiFromHTTPError converts a decoded HTTPError listing aggregated errors to a MultiError.
+// The aggregate keeps the code, message, reason and status of the response itself.
+func multiFromHTTPError(httpErr HTTPError, statusCode int, converter CodeConverter) *MultiError {
+	m := &MultiError{}
+	for _, child := range httpErr.Errors {
+		childStatus := child.Status
+		if childStatus == 0 {
+			childStatus = statusCode
+		}
+		m.errs = append(m.errs, fromHTTPError(child, childStatus, converter))
+	}
+
+	grpcCode := converter.HTTPToGRPC(statusCode)
+	m.grpcCode = &grpcCode
+	m.httpCode = statusCode
+	m.reason = NewDefaultReason(httpErr.Code, httpErr.Message).WithReason(httpErr.Reason)
+	m.metadata = httpErr.metadataValues()
+	m.retry, m.retryDelay = httpErr.retry()
+	return m
+}
+
+// WriteError writes err to an HTTP response with the status code of err, after logging it
+// with the response logger and the context of the request. Errors that are not an Error
+// are wrapped as by Join. It does nothing if err is nil.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	xe := asError(err)
+	LogResponse(r.Context(), xe)
+	writeHTTP(w, xe.GetHTTPCode(), toHTTPError(xe))
+}
 
 // WriteHTTPError writes a structured error to an HTTP response.
 // This is a convenience function for creating and writing an error in one step.
@@ -79,15 +303,24 @@ func WriteHTTPError(w http.ResponseWriter, code string, message string, httpCode
 	err := NewWithHTTPAndGRPC(code, message, httpCode, DefaultConverter.HTTPToGRPC(httpCode))
 	var se *StructuredError
 	if errors.As(err, &se) {
+		LogResponse(context.Background(), se)
 		se.ToHTTP(w)
 	}
 }
 
 // WriteStandardHTTPError writes a standard error to an HTTP response.
-// It uses the standard error code mapping to determine the appropriate HTTP status code.
+// It uses the standard code catalog to determine the appropriate HTTP status code.
+// See WriteCodeHTTPError for a Code from the catalog.
 func WriteStandardHTTPError(w http.ResponseWriter, code string, message string) {
-	err := New(code, message)
+	WriteCodeHTTPError(w, Code(code), message)
+}
+
+// WriteCodeHTTPError writes an error with a code from the code catalog to an HTTP response,
+// with the HTTP status code of the code.
+func WriteCodeHTTPError(w http.ResponseWriter, code Code, message string) {
+	err := NewStandardError(code, message)
 	if se, ok := err.(*StructuredError); ok {
+		LogResponse(context.Background(), se)
 		se.ToHTTP(w)
 	} else {
 		// Fallback if not a StructuredError (should never happen)
@@ -0,0 +1,65 @@
+package xerr
+
+// MergePolicy selects the attributes that the outer layer created by WrapWithReason
+// inherits from the Error it wraps. Attributes that are not inherited are derived from
+// the code of the outer layer, as for New, and options passed when wrapping override both:
+//
+//	// Keep the status of the inner error, but not its metadata
+//	err := xerr.WrapWithPolicy(err, reason, xerr.InheritStatus|xerr.InheritRetry)
+//
+// The inner error is never modified and stays in the chain, so handlers see both the
+// outer and the inner codes, see Chain and Codes.
+type MergePolicy uint8
+
+const (
+	// InheritStatus inherits the HTTP and gRPC status codes.
+	InheritStatus MergePolicy = 1 << iota
+	// InheritMetadata inherits the metadata entries, including error details such as field violations.
+	InheritMetadata
+	// InheritDomain inherits the ErrorInfo domain.
+	InheritDomain
+	// InheritSeverity inherits the severity.
+	InheritSeverity
+	// InheritRetry inherits the retry classification and delay.
+	InheritRetry
+)
+
+const (
+	// InheritNone derives every attribute of the outer layer from its own code.
+	InheritNone MergePolicy = 0
+	// InheritAll inherits every attribute that the outer layer does not override.
+	InheritAll = InheritStatus | InheritMetadata | InheritDomain | InheritSeverity | InheritRetry
+)
+
+// DefaultMergePolicy is the policy used by WrapWithReason.
+var DefaultMergePolicy = InheritAll
+
+// Has reports whether the policy inherits all the attributes of flag.
+func (p MergePolicy) Has(flag MergePolicy) bool {
+	return p&flag == flag
+}
+
+// inherit copies the attributes selected by the policy from inner to e,
+// which must not be shared yet.
+func (p MergePolicy) inherit(e *StructuredError, inner Error) {
+	if p.Has(InheritStatus) {
+		e.HTTPCode = inner.GetHTTPCode()
+		e.GRPCCode = inner.GetGRPCCode()
+	}
+	if p.Has(InheritMetadata) {
+		if metadata := inner.GetMetadataValues(); len(metadata) > 0 {
+			e.replaceMetadata(metadata)
+		}
+		e.redaction = redactionOf(inner)
+	}
+	if se, ok := inner.(*StructuredError); ok && p.Has(InheritDomain) {
+		e.Domain = se.Domain
+	}
+	if p.Has(InheritSeverity) {
+		e.severity = inner.GetSeverity()
+	}
+	if p.Has(InheritRetry) {
+		e.retry = inner.GetRetryability()
+		e.retryDelay = inner.GetRetryDelay()
+	}
+}
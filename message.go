@@ -0,0 +1,76 @@
+package xerr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MessagePolicy selects the developer-facing message sent over HTTP and gRPC for errors
+// annotated with WrapMsg. Error always renders the full breadcrumb path, for logs.
+type MessagePolicy int
+
+const (
+	// MessageInnermost sends the message of the annotated error, without breadcrumbs.
+	MessageInnermost MessagePolicy = iota
+	// MessageFullPath sends the breadcrumbs followed by the message of the annotated error,
+	// such as "load user: query users: row not found".
+	MessageFullPath
+	// MessageOutermost sends the outermost breadcrumb, or the message of the error
+	// if it has no breadcrumbs.
+	MessageOutermost
+)
+
+// DefaultMessagePolicy is the MessagePolicy of HTTPError.Message and of gRPC status messages.
+var DefaultMessagePolicy = MessageInnermost
+
+// String returns the name of the policy.
+func (p MessagePolicy) String() string {
+	switch p {
+	case MessageInnermost:
+		return "innermost"
+	case MessageFullPath:
+		return "full path"
+	case MessageOutermost:
+		return "outermost"
+	default:
+		return "MessagePolicy(" + strconv.Itoa(int(p)) + ")"
+	}
+}
+
+// Breadcrumbs returns the annotations added with WrapMsg to the outermost Error in the
+// chain of err, outermost first.
+func Breadcrumbs(err error) []string {
+	var breadcrumbs []string
+	for {
+		first, _ := First(err)
+		e, ok := first.(*StructuredError)
+		if !ok || e.context == "" {
+			return breadcrumbs
+		}
+		breadcrumbs = append(breadcrumbs, e.context)
+		err = e.Cause
+	}
+}
+
+// transportMessage returns the developer-facing message of err under the DefaultMessagePolicy,
+// or the generic message that replaces it under the DefaultExposurePolicy.
+func transportMessage(err Error) string {
+	if m, ok := err.(*MultiError); ok && m.reason == nil {
+		return transportMessage(m.Representative())
+	}
+	if policy := DefaultExposurePolicy; policy.hides(err) {
+		return policy.message(err)
+	}
+	breadcrumbs := Breadcrumbs(err)
+	if len(breadcrumbs) == 0 {
+		return err.GetMessage()
+	}
+	switch DefaultMessagePolicy {
+	case MessageFullPath:
+		return strings.Join(append(breadcrumbs, err.GetMessage()), ": ")
+	case MessageOutermost:
+		return breadcrumbs[0]
+	default:
+		return err.GetMessage()
+	}
+}
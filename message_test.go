@@ -0,0 +1,88 @@
+package xerr
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func withMessagePolicy(t *testing.T, policy MessagePolicy) {
+	t.Helper()
+	previous := DefaultMessagePolicy
+	DefaultMessagePolicy = policy
+	t.Cleanup(func() { DefaultMessagePolicy = previous })
+}
+
+func TestWrapMsg(t *testing.T) {
+	inner := Wrap(sql.ErrNoRows, NOT_FOUND, WithMetadata("table", "users"))
+	err := WrapMsgf(fmt.Errorf("repository: %w", WrapMsg(inner, "query users")), "load user %d", 42)
+
+	if got, want := err.Error(), "load user 42: repository: query users: [NOT_FOUND] sql: no rows in result set"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if err.GetCode() != string(NOT_FOUND) || err.GetMessage() != inner.GetMessage() {
+		t.Fatalf("expected the code and message to be kept, got %s %q", err.GetCode(), err.GetMessage())
+	}
+	if v, _ := err.GetMetadataValue("table"); v.String() != "users" || err.GetHTTPCode() != inner.GetHTTPCode() {
+		t.Fatal("expected the attributes of the inner error to be inherited")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatal("expected the original error to stay reachable")
+	}
+	if got := Breadcrumbs(err); !slices.Equal(got, []string{"load user 42", "query users"}) {
+		t.Fatalf("unexpected breadcrumbs %v", got)
+	}
+	if Breadcrumbs(inner) != nil {
+		t.Fatal("expected no breadcrumbs on an error that was not annotated")
+	}
+
+	// Errors without an Error in their chain are classified first
+	plain := WrapMsg(sql.ErrNoRows, "load user")
+	if plain.GetCode() != string(NOT_FOUND) || plain.Error() != "load user: [NOT_FOUND] sql: no rows in result set" {
+		t.Fatalf("expected a classified annotated error, got %q", plain.Error())
+	}
+
+	if WrapMsg(nil, "ignored") != nil || WrapMsgf(nil, "ignored") != nil {
+		t.Fatal("expected nil when wrapping nil")
+	}
+}
+
+func TestMessagePolicy(t *testing.T) {
+	err := WrapMsg(WrapMsg(New(string(NOT_FOUND), "row not found"), "query users"), "load user")
+	tests := []struct {
+		policy MessagePolicy
+		want   string
+	}{
+		{MessageInnermost, "row not found"},
+		{MessageFullPath, "load user: query users: row not found"},
+		{MessageOutermost, "load user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			withMessagePolicy(t, tt.policy)
+
+			body, _ := err.(*StructuredError).ToHTTPJSON()
+			var httpErr HTTPError
+			if err := json.Unmarshal(body, &httpErr); err != nil {
+				t.Fatal(err)
+			}
+			if httpErr.Message != tt.want {
+				t.Errorf("expected HTTP message %q, got %q", tt.want, httpErr.Message)
+			}
+			if got := err.(*StructuredError).ToGRPCStatus().Message(); got != tt.want {
+				t.Errorf("expected gRPC message %q, got %q", tt.want, got)
+			}
+			if got := Join(err).(*MultiError).ToGRPCStatus().Message(); got != tt.want {
+				t.Errorf("expected aggregated gRPC message %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	withMessagePolicy(t, MessageOutermost)
+	if got := transportMessage(NewStandardError(NOT_FOUND, "not annotated")); got != "not annotated" {
+		t.Fatalf("expected the message of an error without breadcrumbs, got %q", got)
+	}
+}
@@ -0,0 +1,459 @@
+package xerr
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Precedence selects the representative error of a MultiError.
+// It is called with at least one error and returns the index of the representative.
+type Precedence func(errs []Error) int
+
+// PrecedenceFirst selects the first error.
+func PrecedenceFirst(_ []Error) int {
+	return 0
+}
+
+// PrecedenceFirstServerFault selects the first error with a 5xx HTTP status code,
+// or the first error if none of them is a server fault.
+func PrecedenceFirstServerFault(errs []Error) int {
+	for i, err := range errs {
+		if err.GetHTTPCode() >= 500 {
+			return i
+		}
+	}
+	return 0
+}
+
+// PrecedenceMostSevere selects the error with the highest Severity.
+// Errors of the same severity are ranked by their gRPC status code, and remaining
+// ties are resolved in favor of the earlier error.
+func PrecedenceMostSevere(errs []Error) int {
+	best := 0
+	for i, err := range errs {
+		if moreSevere(err, errs[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// moreSevere reports whether a is strictly more severe than b.
+func moreSevere(a, b Error) bool {
+	if sa, sb := a.GetSeverity(), b.GetSeverity(); sa != sb {
+		return sa > sb
+	}
+	return grpcSeverityRank(a.GetGRPCCode()) > grpcSeverityRank(b.GetGRPCCode())
+}
+
+// grpcSeverityRank orders gRPC status codes from least to most severe.
+func grpcSeverityRank(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return 0
+	case codes.Canceled:
+		return 1
+	case codes.NotFound, codes.AlreadyExists, codes.OutOfRange:
+		return 2
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.Aborted:
+		return 3
+	case codes.Unauthenticated, codes.PermissionDenied, codes.ResourceExhausted:
+		return 4
+	case codes.Unimplemented:
+		return 5
+	case codes.DeadlineExceeded, codes.Unavailable:
+		return 6
+	case codes.Unknown:
+		return 7
+	case codes.Internal:
+		return 8
+	case codes.DataLoss:
+		return 9
+	default:
+		return 7
+	}
+}
+
+// DefaultPrecedence is the Precedence used by Join.
+var DefaultPrecedence Precedence = PrecedenceMostSevere
+
+// MultiError aggregates several errors into a single Error.
+// The codes, message and reason of the aggregate are taken from a representative
+// error selected by a Precedence, unless they are overridden with the With* modifiers.
+// Like StructuredError, a MultiError is immutable: modifiers return a fresh copy.
+type MultiError struct {
+	errs       []Error
+	precedence Precedence
+	reason     Reason           // Overrides the representative's reason when set
+	httpCode   int              // Overrides the representative's HTTP code when non-zero
+	grpcCode   *codes.Code      // Overrides the representative's gRPC code when set
+	metadata   map[string]Value // Merged over the representative's metadata
+	severity   Severity         // Overrides the highest severity of the errors when set
+	retry      Retryability     // Overrides the representative's retry classification when set
+	retryDelay time.Duration    // Delay before retrying, for RetryableAfterDelay
+}
+
+// Join aggregates the non-nil errors into a MultiError using DefaultPrecedence.
+// Errors that are not an Error are wrapped with WrapDefault.
+// Join returns nil if every error is nil.
+func Join(errs ...error) Error {
+	return JoinWithPrecedence(DefaultPrecedence, errs...)
+}
+
+// JoinWithPrecedence aggregates the non-nil errors into a MultiError using the given Precedence.
+// Errors that are not an Error are wrapped with WrapDefault, or with WrapWithReason and the
+// reason of the first Error in their chain, so that context added with fmt.Errorf is kept.
+// JoinWithPrecedence returns nil if every error is nil.
+func JoinWithPrecedence(precedence Precedence, errs ...error) Error {
+	m := &MultiError{precedence: precedence}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		m.errs = append(m.errs, asError(err))
+	}
+	if len(m.errs) == 0 {
+		return nil
+	}
+	return m
+}
+
+// asError returns err as an Error. Errors that are not an Error are wrapped with WrapDefault,
+// or with WrapWithReason and the reason of the first Error in their chain, so that context
+// added with fmt.Errorf is kept.
+func asError(err error) Error {
+	if xe, ok := err.(Error); ok {
+		return xe
+	}
+	if inner, found := First(err); found {
+		return WrapWithReason(err, inner.GetReason())
+	}
+	return WrapDefault(err)
+}
+
+// Errors returns the aggregated errors.
+func (m *MultiError) Errors() []Error {
+	return append([]Error(nil), m.errs...)
+}
+
+// emptyRepresentative stands in for the representative of a MultiError without errors,
+// such as the zero value, so that its methods don't panic.
+var emptyRepresentative Error = &StructuredError{
+	reason:   NewDefaultReason(string(UNKNOWN), "no errors"),
+	GRPCCode: codes.Unknown,
+	HTTPCode: http.StatusInternalServerError,
+}
+
+// Representative returns the error selected by the Precedence of the MultiError.
+// A MultiError without errors, such as the zero value, is represented by an UNKNOWN error.
+func (m *MultiError) Representative() Error {
+	if i := m.representativeIndex(); i >= 0 {
+		return m.errs[i]
+	}
+	return emptyRepresentative
+}
+
+// representativeIndex returns the index of the representative error, or -1 if there are no errors.
+func (m *MultiError) representativeIndex() int {
+	if len(m.errs) == 0 {
+		return -1
+	}
+	precedence := m.precedence
+	if precedence == nil {
+		precedence = DefaultPrecedence
+	}
+	i := precedence(m.errs)
+	if i < 0 || i >= len(m.errs) {
+		return 0
+	}
+	return i
+}
+
+// clone returns a copy of the MultiError with its own copy of the metadata.
+func (m *MultiError) clone() *MultiError {
+	c := *m
+	c.metadata = copyMetadata(m.metadata)
+	return &c
+}
+
+// Error implements the error interface.
+// It lists the messages of all aggregated errors.
+func (m *MultiError) Error() string {
+	if len(m.errs) == 1 {
+		return m.errs[0].Error()
+	}
+	msgs := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		msgs[i] = err.Error()
+	}
+	return strconv.Itoa(len(m.errs)) + " errors: " + strings.Join(msgs, "; ")
+}
+
+// GetReason returns the Reason of the representative error, unless overridden.
+func (m *MultiError) GetReason() Reason {
+	if m.reason != nil {
+		return m.reason
+	}
+	return m.Representative().GetReason()
+}
+
+// GetGRPCCode returns the gRPC status code of the representative error, unless overridden.
+func (m *MultiError) GetGRPCCode() codes.Code {
+	if m.grpcCode != nil {
+		return *m.grpcCode
+	}
+	return m.Representative().GetGRPCCode()
+}
+
+// GetHTTPCode returns the HTTP status code of the representative error, unless overridden.
+func (m *MultiError) GetHTTPCode() int {
+	if m.httpCode != 0 {
+		return m.httpCode
+	}
+	return m.Representative().GetHTTPCode()
+}
+
+// GetMetadata returns the metadata of the representative error merged with
+// the metadata added to the MultiError itself, with every value in its string encoding.
+func (m *MultiError) GetMetadata() map[string]string {
+	return stringMetadata(m.GetMetadataValues())
+}
+
+// GetMetadataValue returns the typed metadata value for key from the metadata
+// added to the MultiError or, failing that, from the representative error.
+func (m *MultiError) GetMetadataValue(key string) (Value, bool) {
+	if v, ok := m.metadata[key]; ok {
+		return v, true
+	}
+	return m.Representative().GetMetadataValue(key)
+}
+
+// GetMetadataValues returns the typed metadata of the representative error merged with
+// the metadata added to the MultiError itself.
+func (m *MultiError) GetMetadataValues() map[string]Value {
+	metadata := m.Representative().GetMetadataValues()
+	if len(m.metadata) > 0 && metadata == nil {
+		metadata = make(map[string]Value, len(m.metadata))
+	}
+	for k, v := range m.metadata {
+		metadata[k] = v
+	}
+	return metadata
+}
+
+// GetCause returns the representative error.
+func (m *MultiError) GetCause() error {
+	return m.Representative()
+}
+
+// GetCode returns the error code of the representative error, unless overridden.
+func (m *MultiError) GetCode() string {
+	if m.reason != nil {
+		return m.reason.Code()
+	}
+	return m.Representative().GetCode()
+}
+
+// GetMessage returns the message of the representative error, unless overridden.
+func (m *MultiError) GetMessage() string {
+	if m.reason != nil {
+		return m.reason.Message()
+	}
+	return m.Representative().GetMessage()
+}
+
+// GetUserReason returns the user-facing reason of the representative error, unless overridden.
+func (m *MultiError) GetUserReason() string {
+	if m.reason != nil {
+		return m.reason.Reason()
+	}
+	return m.Representative().GetUserReason()
+}
+
+// WithReason returns a copy of the MultiError with a user-facing reason.
+func (m *MultiError) WithReason(reason string) Error {
+	c := m.clone()
+	c.reason = NewDefaultReason(m.GetCode(), m.GetMessage()).WithReason(reason)
+	return c
+}
+
+// WithGRPCCode returns a copy of the MultiError with the given gRPC status code.
+func (m *MultiError) WithGRPCCode(code codes.Code) Error {
+	c := m.clone()
+	c.grpcCode = &code
+	return c
+}
+
+// WithHTTPCode returns a copy of the MultiError with the given HTTP status code.
+func (m *MultiError) WithHTTPCode(code int) Error {
+	c := m.clone()
+	c.httpCode = code
+	return c
+}
+
+// WithMetadata returns a copy of the MultiError with the given string metadata entry added.
+func (m *MultiError) WithMetadata(key string, value string) Error {
+	return m.WithMetadataValue(key, StringValue(value))
+}
+
+// WithMetadataValue returns a copy of the MultiError with the given typed metadata entry added.
+func (m *MultiError) WithMetadataValue(key string, value any) Error {
+	c := m.clone()
+	if c.metadata == nil {
+		c.metadata = make(map[string]Value)
+	}
+	c.metadata[key] = AnyValue(value)
+	return c
+}
+
+// Is reports whether target is this MultiError or matches any of the aggregated errors.
+func (m *MultiError) Is(target error) bool {
+	if t, ok := target.(*MultiError); ok && t == m {
+		return true
+	}
+	for _, err := range m.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first aggregated error that matches target, as errors.As does,
+// so that errors.As inspects every aggregated error and not only the representative.
+func (m *MultiError) As(target any) bool {
+	for _, err := range m.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the representative error, as GetCause does.
+// Use Errors or Chain to visit every aggregated error.
+func (m *MultiError) Unwrap() error {
+	if len(m.errs) == 0 {
+		return nil
+	}
+	return m.Representative()
+}
+
+// branches returns the aggregated errors as plain errors.
+func (m *MultiError) branches() []error {
+	errs := make([]error, len(m.errs))
+	for i, err := range m.errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+// httpError builds the HTTPError response body, listing every aggregated error.
+func (m *MultiError) httpError() HTTPError {
+	httpErr := HTTPError{
+		Code:    m.GetCode(),
+		Message: transportMessage(m),
+		Reason:  m.GetUserReason(),
+	}
+	httpErr.setMetadata(publicMetadata(m, m.GetMetadataValues()))
+	httpErr.annotate(m)
+	for _, err := range m.errs {
+		child := toHTTPError(err)
+		child.Status = err.GetHTTPCode()
+		httpErr.Errors = append(httpErr.Errors, child)
+	}
+	return httpErr
+}
+
+// ToHTTP writes the MultiError as JSON to the http.ResponseWriter with the status code
+// of the representative error. Every aggregated error is listed in the "errors" field.
+func (m *MultiError) ToHTTP(w http.ResponseWriter) {
+	writeHTTP(w, m.GetHTTPCode(), m.httpError())
+}
+
+// ToHTTPJSON converts the MultiError to an HTTP JSON error response.
+// It returns the JSON bytes and the HTTP status code.
+func (m *MultiError) ToHTTPJSON() ([]byte, int) {
+	return marshalHTTP(m.GetHTTPCode(), m.httpError())
+}
+
+// ToGRPCStatus converts the MultiError to a gRPC status.Status with the code and message
+// of the representative error. The status carries the ErrorInfo of every aggregated error,
+// starting with the representative one, and the metadata added to the MultiError itself
+// as a google.protobuf.Struct.
+func (m *MultiError) ToGRPCStatus() *status.Status {
+	st := status.New(m.GetGRPCCode(), transportMessage(m))
+
+	rep := m.representativeIndex()
+	details := make([]protoadapt.MessageV1, 0, len(m.errs)+3)
+	details = append(details, errorInfoOf(m.Representative()))
+	for i, err := range m.errs {
+		if i != rep {
+			details = append(details, errorInfoOf(err))
+		}
+	}
+	if metadata := publicMetadata(m, copyMetadata(m.metadata)); len(metadata) > 0 {
+		details = append(details, metadataToStruct(metadata))
+	}
+	if retryInfo := retryInfoOf(m); retryInfo != nil {
+		details = append(details, retryInfo)
+	}
+	if userReason := m.GetUserReason(); userReason != "" {
+		details = append(details, &errdetails.LocalizedMessage{
+			Locale:  "en-US",
+			Message: userReason,
+		})
+	}
+	return withDetails(st, details...)
+}
+
+// errorInfoOf returns the ErrorInfo detail of any Error.
+// The occurrence of the error is added under reserved metadata keys, and so is the
+// retry classification when it differs from the one derived from the gRPC status code.
+func errorInfoOf(err Error) *errdetails.ErrorInfo {
+	var info *errdetails.ErrorInfo
+	if se, ok := err.(*StructuredError); ok {
+		info = se.GetErrorInfo()
+	} else {
+		info = &errdetails.ErrorInfo{
+			Reason:   err.GetCode(),
+			Domain:   defaultDomain,
+			Metadata: stringMetadata(publicMetadata(err, err.GetMetadataValues())),
+		}
+	}
+	reserved := make(map[string]string)
+	if retryable, _ := encodeRetry(err, err.GetGRPCCode()); retryable != nil {
+		reserved[retryableKey] = strconv.FormatBool(*retryable)
+	}
+	if id := err.GetOccurrenceID(); id != "" {
+		reserved[occurrenceIDKey] = id
+	}
+	if ts := err.GetTimestamp(); !ts.IsZero() {
+		reserved[timestampKey] = ts.Format(time.RFC3339Nano)
+	}
+	if len(reserved) > 0 && info.Metadata == nil {
+		info.Metadata = make(map[string]string, len(reserved))
+	}
+	for k, v := range reserved {
+		info.Metadata[k] = v
+	}
+	return info
+}
+
+// retryInfoOf returns the RetryInfo detail of err, or nil if it has no retry delay.
+func retryInfoOf(err Error) *errdetails.RetryInfo {
+	delay := err.GetRetryDelay()
+	if delay <= 0 {
+		return nil
+	}
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}
+}
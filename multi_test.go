@@ -0,0 +1,204 @@
+package xerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestJoinNil(t *testing.T) {
+	if Join() != nil || Join(nil, nil) != nil {
+		t.Fatal("expected nil when joining no errors")
+	}
+}
+
+func TestJoinPrecedence(t *testing.T) {
+	invalid := NewStandardError(INVALID_ARGUMENT, "name is required")
+	unavailable := NewStandardError(UNAVAILABLE, "inventory is down")
+	internal := NewStandardError(INTERNAL, "pricing failed")
+
+	tests := []struct {
+		name       string
+		precedence Precedence
+		want       string
+	}{
+		{"first", PrecedenceFirst, "INVALID_ARGUMENT"},
+		{"first server fault", PrecedenceFirstServerFault, "UNAVAILABLE"},
+		{"most severe", PrecedenceMostSevere, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := JoinWithPrecedence(tt.precedence, invalid, unavailable, internal)
+			if err.GetCode() != tt.want {
+				t.Fatalf("expected representative %s, got %s", tt.want, err.GetCode())
+			}
+		})
+	}
+}
+
+func TestJoinUnwrap(t *testing.T) {
+	notFound := NewStandardError(NOT_FOUND, "user not found")
+	plain := errors.New("plain failure")
+	err := Join(notFound, plain)
+
+	if !errors.Is(err, notFound) || !errors.Is(err, plain) {
+		t.Fatal("expected errors.Is to find every aggregated error")
+	}
+	var se *StructuredError
+	if !errors.As(err, &se) || se.GetCode() != "NOT_FOUND" {
+		t.Fatalf("expected errors.As to find NOT_FOUND, got %v", se)
+	}
+	if err.Error() != "2 errors: [NOT_FOUND] user not found; [UNKNOWN] plain failure" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+
+	// The representative is the cause through the Error interface
+	if err.Unwrap() != err.(*MultiError).Representative() {
+		t.Fatal("expected Unwrap to return the representative error")
+	}
+	var sentinel *quotaError
+	if !errors.As(Join(notFound, WrapDefault(&quotaError{})), &sentinel) {
+		t.Fatal("expected errors.As to inspect errors other than the representative")
+	}
+}
+
+func TestJoinKeepsContext(t *testing.T) {
+	errDB := errors.New("connection reset")
+	wrapped := fmt.Errorf("load user: %w", Wrap(errDB, UNAVAILABLE))
+	err := Join(wrapped).(*MultiError).Errors()[0]
+
+	if err.GetCode() != string(UNAVAILABLE) || err.GetHTTPCode() != http.StatusServiceUnavailable {
+		t.Fatalf("expected the code of the inner error, got %s", err.GetCode())
+	}
+	if err.GetCause() != wrapped || !errors.Is(err, errDB) {
+		t.Fatal("expected the context added with fmt.Errorf to stay in the chain")
+	}
+}
+
+func TestEmptyMultiError(t *testing.T) {
+	var m MultiError
+	if m.GetCode() != string(UNKNOWN) || m.GetHTTPCode() != http.StatusInternalServerError || m.Unwrap() != nil {
+		t.Fatalf("expected an empty MultiError to be UNKNOWN, got %s", m.GetCode())
+	}
+	if _, status := m.ToHTTPJSON(); status != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", status)
+	}
+	if m.ToGRPCStatus().Code() != codes.Unknown {
+		t.Fatal("expected codes.Unknown")
+	}
+}
+
+func TestMultiErrorModifiers(t *testing.T) {
+	base := Join(NewStandardError(NOT_FOUND, "a"), NewStandardError(INVALID_ARGUMENT, "b"))
+	modified := base.WithHTTPCode(http.StatusMultiStatus).WithMetadata("batch", "7").WithReason("Some items failed")
+
+	if base.GetHTTPCode() != http.StatusBadRequest || base.GetUserReason() != "" || len(base.GetMetadata()) != 0 {
+		t.Fatal("expected base to be unchanged")
+	}
+	if modified.GetHTTPCode() != http.StatusMultiStatus || modified.GetMetadata()["batch"] != "7" ||
+		modified.GetUserReason() != "Some items failed" || modified.GetCode() != "INVALID_ARGUMENT" {
+		t.Fatalf("unexpected modified error: %d %v %q %s", modified.GetHTTPCode(), modified.GetMetadata(),
+			modified.GetUserReason(), modified.GetCode())
+	}
+}
+
+func TestWrapDefaultJoined(t *testing.T) {
+	err := WrapDefault(errors.Join(NewStandardError(NOT_FOUND, "missing"), errors.New("other")))
+	if _, ok := err.(*MultiError); !ok {
+		t.Fatalf("expected *MultiError, got %T", err)
+	}
+	if err.GetCode() != "UNKNOWN" {
+		t.Fatalf("expected most severe code UNKNOWN, got %s", err.GetCode())
+	}
+}
+
+func TestMultiErrorHTTPRoundTrip(t *testing.T) {
+	err := JoinWithPrecedence(PrecedenceFirst,
+		NewStandardError(INVALID_ARGUMENT, "name is required").WithMetadata("field", "name"),
+		NewStandardError(NOT_FOUND, "group not found"),
+	).(*MultiError)
+
+	rec := httptest.NewRecorder()
+	err.ToHTTP(rec)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+
+	var httpErr HTTPError
+	if e := json.Unmarshal(body, &httpErr); e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+	if len(httpErr.Errors) != 2 || httpErr.Errors[1].Code != "NOT_FOUND" || httpErr.Errors[1].Status != http.StatusNotFound {
+		t.Fatalf("expected every child in the body, got %+v", httpErr.Errors)
+	}
+
+	decoded, e := FromHTTPJSON(body, rec.Code)
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+	multi, ok := decoded.(*MultiError)
+	if !ok {
+		t.Fatalf("expected *MultiError, got %T", decoded)
+	}
+	if multi.GetCode() != "INVALID_ARGUMENT" || multi.GetHTTPCode() != http.StatusBadRequest {
+		t.Fatalf("unexpected representative %s/%d", multi.GetCode(), multi.GetHTTPCode())
+	}
+	children := multi.Errors()
+	if len(children) != 2 || children[1].GetHTTPCode() != http.StatusNotFound || children[1].GetMessage() != "group not found" {
+		t.Fatalf("unexpected children %v", children)
+	}
+}
+
+func TestMultiErrorGRPCStatus(t *testing.T) {
+	withExposurePolicy(t, nil, false)
+	err := JoinWithPrecedence(PrecedenceMostSevere,
+		NewStandardError(INVALID_ARGUMENT, "name is required"),
+		NewStandardError(UNAVAILABLE, "inventory is down").WithMetadata("host", "inv-1"),
+	).(*MultiError)
+
+	st := err.ToGRPCStatus()
+	if st.Code() != codes.Unavailable || st.Message() != "inventory is down" {
+		t.Fatalf("unexpected status %s: %s", st.Code(), st.Message())
+	}
+	var reasons []string
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			reasons = append(reasons, info.Reason)
+		}
+	}
+	if len(reasons) != 2 || reasons[0] != "UNAVAILABLE" || reasons[1] != "INVALID_ARGUMENT" {
+		t.Fatalf("expected ErrorInfo for every child, representative first, got %v", reasons)
+	}
+
+	decoded, ok := FromGRPCStatus(st).(*MultiError)
+	if !ok {
+		t.Fatal("expected FromGRPCStatus to return a *MultiError")
+	}
+	if decoded.GetCode() != "UNAVAILABLE" || decoded.GetGRPCCode() != codes.Unavailable || decoded.GetMetadata()["host"] != "inv-1" {
+		t.Fatalf("unexpected decoded error %s/%s/%v", decoded.GetCode(), decoded.GetGRPCCode(), decoded.GetMetadata())
+	}
+	if len(decoded.Errors()) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(decoded.Errors()))
+	}
+
+	// The metadata of the aggregate itself is restored on the aggregate
+	withBatch := err.WithMetadata("batch", "7").WithMetadataValue("failed", 2).(*MultiError)
+	decoded = FromGRPCStatus(withBatch.ToGRPCStatus()).(*MultiError)
+	if decoded.GetMetadata()["batch"] != "7" || decoded.GetMetadata()["host"] != "inv-1" {
+		t.Fatalf("expected the aggregate and representative metadata, got %v", decoded.GetMetadata())
+	}
+	if v := mustValue(t, decoded, "failed"); v.Kind() != KindInt {
+		t.Fatalf("expected the typed aggregate metadata, got %v (%s)", v, v.Kind())
+	}
+	if _, ok := decoded.Errors()[0].GetMetadataValue("batch"); ok {
+		t.Fatal("expected the aggregate metadata to stay off the children")
+	}
+}
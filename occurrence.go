@@ -0,0 +1,154 @@
+package xerr
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// IDGenerator returns a new, unique occurrence ID.
+type IDGenerator func() string
+
+// Clock returns the current time.
+type Clock func() time.Time
+
+// DefaultIDGenerator generates the occurrence ID of every error instance.
+// It defaults to NewULID and can be replaced, for example with NewUUIDv7.
+// Setting it to nil disables occurrence IDs.
+var DefaultIDGenerator IDGenerator = NewULID
+
+// DefaultClock provides the timestamp of every error instance and of generated IDs.
+// Setting it to nil disables timestamps.
+var DefaultClock Clock = time.Now
+
+// occurrence identifies a single error instance. The timestamp is taken from DefaultClock
+// when the error is created. The ID is assigned from DefaultIDGenerator when it is first read,
+// typically when the error is logged or written out, so that errors that are never observed
+// don't pay for generating it. New, wrapping and modified errors each get their own occurrence.
+type occurrence struct {
+	once      sync.Once
+	id        string
+	timestamp time.Time
+}
+
+// newOccurrence returns a fresh occurrence created now, whose ID is assigned when it is first read.
+func newOccurrence() *occurrence {
+	o := &occurrence{}
+	if clock := DefaultClock; clock != nil {
+		o.timestamp = clock()
+	}
+	return o
+}
+
+// decodedOccurrence returns the occurrence received with an error. It is never reassigned.
+func decodedOccurrence(id string, timestamp time.Time) *occurrence {
+	o := &occurrence{id: id, timestamp: timestamp}
+	o.once.Do(func() {})
+	return o
+}
+
+// getID returns the ID of the occurrence, assigning it on the first call.
+// A nil occurrence has none.
+func (o *occurrence) getID() string {
+	if o == nil {
+		return ""
+	}
+	o.once.Do(func() {
+		if generate := DefaultIDGenerator; generate != nil {
+			o.id = generate()
+		}
+	})
+	return o.id
+}
+
+// getTimestamp returns the creation time of the occurrence.
+// A nil occurrence has none.
+func (o *occurrence) getTimestamp() time.Time {
+	if o == nil {
+		return time.Time{}
+	}
+	return o.timestamp
+}
+
+// now returns the current time from DefaultClock, or from time.Now if it is nil.
+func now() time.Time {
+	if clock := DefaultClock; clock != nil {
+		return clock()
+	}
+	return time.Now()
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a new ULID: a 26-character, lexicographically sortable identifier
+// made of a 48-bit millisecond timestamp from DefaultClock and 80 random bits.
+func NewULID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(now().UnixMilli())<<16)
+	_, _ = rand.Read(b[6:])
+
+	// Encode the 128 bits as 26 base32 characters, the first one holding only 3 bits
+	var out [26]byte
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// NewUUIDv7 returns a new version 7 UUID in its canonical textual form:
+// a 48-bit millisecond timestamp from DefaultClock followed by random bits.
+func NewUUIDv7() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(now().UnixMilli())<<16)
+	_, _ = rand.Read(b[6:])
+	b[6] = b[6]&0x0f | 0x70 // Version 7
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
+
+// GetOccurrenceID returns the unique ID of this error instance, or an empty string
+// if it has none. The ID is assigned when it is first read, by this method, LogValue or
+// the HTTP and gRPC writers, and modified copies of an error get a new one.
+func (e *StructuredError) GetOccurrenceID() string {
+	return e.occurrence.getID()
+}
+
+// GetTimestamp returns the time this error instance was created, or the zero time
+// if it is unknown. Modified copies of an error get the time they were made.
+func (e *StructuredError) GetTimestamp() time.Time {
+	return e.occurrence.getTimestamp()
+}
+
+// GetOccurrenceID returns the occurrence ID of the representative error.
+func (m *MultiError) GetOccurrenceID() string {
+	return m.Representative().GetOccurrenceID()
+}
+
+// GetTimestamp returns the timestamp of the representative error.
+func (m *MultiError) GetTimestamp() time.Time {
+	return m.Representative().GetTimestamp()
+}
+
+// Reserved ErrorInfo metadata keys carrying the occurrence of an error over gRPC.
+const (
+	occurrenceIDKey = "xerr_occurrence_id"
+	timestampKey    = "xerr_timestamp"
+)
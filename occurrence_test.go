@@ -0,0 +1,124 @@
+package xerr
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+// withOccurrence makes every new error get the given occurrence ID and timestamp for the duration of the test.
+func withOccurrence(t *testing.T, id string, ts time.Time) {
+	t.Helper()
+	previousGenerator, previousClock := DefaultIDGenerator, DefaultClock
+	DefaultIDGenerator = func() string { return id }
+	DefaultClock = func() time.Time { return ts }
+	t.Cleanup(func() { DefaultIDGenerator, DefaultClock = previousGenerator, previousClock })
+}
+
+func TestNewULID(t *testing.T) {
+	ulid := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+	seen := make(map[string]bool)
+	for range 1000 {
+		id := NewULID()
+		if !ulid.MatchString(id) {
+			t.Fatalf("invalid ULID %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate ULID %q", id)
+		}
+		seen[id] = true
+	}
+
+	// The first 10 characters encode the millisecond timestamp, so IDs sort by time
+	withOccurrence(t, "", time.UnixMilli(1))
+	earlier := NewULID()
+	DefaultClock = func() time.Time { return time.UnixMilli(2) }
+	if later := NewULID(); earlier[:10] != "0000000001" || later[:10] != "0000000002" {
+		t.Fatalf("unexpected timestamp encoding %q, %q", earlier, later)
+	}
+}
+
+func TestNewUUIDv7(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	withOccurrence(t, "", time.UnixMilli(0x0123456789ab))
+	id := NewUUIDv7()
+	if !uuid.MatchString(id) || id[:13] != "01234567-89ab" {
+		t.Fatalf("invalid UUIDv7 %q", id)
+	}
+}
+
+func TestOccurrence(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	withOccurrence(t, "first", ts)
+	generated := 0
+	DefaultIDGenerator = func() string {
+		generated++
+		return "first"
+	}
+	err := NewStandardError(NOT_FOUND, "user not found")
+	if generated != 0 {
+		t.Fatal("expected the occurrence ID to be generated when it is first read")
+	}
+
+	if err.GetOccurrenceID() != "first" || !err.GetTimestamp().Equal(ts) || err.GetOccurrenceID() != "first" || generated != 1 {
+		t.Fatalf("unexpected occurrence %q at %s, generated %d times", err.GetOccurrenceID(), err.GetTimestamp(), generated)
+	}
+
+	DefaultIDGenerator = func() string { return "second" }
+	if modified := err.WithMetadata("user_id", "42"); modified.GetOccurrenceID() != "second" || err.GetOccurrenceID() != "first" {
+		t.Fatal("expected a modified copy to get a fresh occurrence")
+	}
+	if wrapped := Wrap(err, INTERNAL); wrapped.GetOccurrenceID() != "second" {
+		t.Fatal("expected a wrapping error to get a fresh occurrence")
+	}
+	if instance := errTestUserNotFound.New(1); instance.GetOccurrenceID() != "second" {
+		t.Fatal("expected a definition instance to get a fresh occurrence")
+	}
+
+	DefaultIDGenerator, DefaultClock = nil, nil
+	if disabled := NewStandardError(NOT_FOUND, ""); disabled.GetOccurrenceID() != "" || !disabled.GetTimestamp().IsZero() {
+		t.Fatal("expected no occurrence when the generator and clock are nil")
+	}
+}
+
+func TestOccurrenceTimestampIsCreationTime(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	withOccurrence(t, "id", created)
+	err := NewStandardError(NOT_FOUND, "user not found")
+
+	// Time passes before the error is first read, by the getters or a writer
+	read := created.Add(time.Minute)
+	DefaultClock = func() time.Time { return read }
+	if got := err.GetTimestamp(); !got.Equal(created) {
+		t.Fatalf("expected the creation time %s, got %s", created, got)
+	}
+	if err.GetOccurrenceID() != "id" || !err.GetTimestamp().Equal(created) {
+		t.Fatal("expected reading the occurrence ID to keep the creation time")
+	}
+	if modified := err.WithMetadata("user_id", "42"); !modified.GetTimestamp().Equal(read) {
+		t.Fatalf("expected a modified copy to get the time it was made, got %s", modified.GetTimestamp())
+	}
+}
+
+func TestOccurrenceRoundTrip(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)
+	withOccurrence(t, "01J0000000000000000000000", ts)
+	err := NewStandardError(NOT_FOUND, "user not found").(*StructuredError)
+
+	body, status := err.ToHTTPJSON()
+	fromHTTP, _ := FromHTTPJSON(body, status)
+	fromGRPC := FromGRPCStatus(err.ToGRPCStatus())
+	for name, decoded := range map[string]Error{"http": fromHTTP, "grpc": fromGRPC} {
+		if decoded.GetOccurrenceID() != err.GetOccurrenceID() || !decoded.GetTimestamp().Equal(ts) {
+			t.Fatalf("%s: expected occurrence to survive, got %q at %s", name, decoded.GetOccurrenceID(), decoded.GetTimestamp())
+		}
+		if _, ok := decoded.GetMetadataValue(occurrenceIDKey); ok {
+			t.Fatalf("%s: expected reserved keys to be removed from the metadata", name)
+		}
+	}
+
+	multi := JoinWithPrecedence(PrecedenceFirst, err, NewStandardError(INVALID_ARGUMENT, "bad name")).(*MultiError)
+	if decoded := FromGRPCStatus(multi.ToGRPCStatus()); decoded.GetOccurrenceID() != err.GetOccurrenceID() {
+		t.Fatalf("expected the representative's occurrence, got %q", decoded.GetOccurrenceID())
+	}
+}
@@ -0,0 +1,181 @@
+package xerr
+
+import (
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Option configures a StructuredError while it is being built.
+// Options are accepted by New, NewStandardError and Wrap, and by the equivalent methods
+// of a Factory. Passed to Define, they describe the defaults of every error created
+// from the Definition. Options are plain values, so a set of options can be shared
+// between definitions and factories, see WithOptions:
+//
+//	err := xerr.New("USER_NOT_FOUND", "user not found",
+//		xerr.WithHTTPStatus(http.StatusNotFound),
+//		xerr.WithGRPCStatus(codes.NotFound),
+//		xerr.WithUserReason("We could not find your account"),
+//		xerr.WithMetadata("user_id", 42),
+//	)
+type Option func(*StructuredError)
+
+// WithOptions combines several options into one, applied in order.
+func WithOptions(opts ...Option) Option {
+	return func(e *StructuredError) {
+		for _, opt := range opts {
+			opt(e)
+		}
+	}
+}
+
+// WithHTTPStatus sets the HTTP status code.
+func WithHTTPStatus(code int) Option {
+	return func(e *StructuredError) {
+		e.HTTPCode = code
+	}
+}
+
+// WithGRPCStatus sets the gRPC status code.
+func WithGRPCStatus(code codes.Code) Option {
+	return func(e *StructuredError) {
+		e.GRPCCode = code
+	}
+}
+
+// WithDomain sets the domain reported in the gRPC ErrorInfo.
+func WithDomain(domain string) Option {
+	return func(e *StructuredError) {
+		e.Domain = domain
+	}
+}
+
+// WithMessage sets the developer-facing message.
+// For a Definition, the message is a template that is formatted with the
+// arguments passed to Definition.New and Definition.Wrap: with fmt.Sprintf,
+// or as a TemplateReason when the only argument is a Params.
+func WithMessage(message string) Option {
+	return func(e *StructuredError) {
+		e.reason = NewDefaultReason(e.GetCode(), message).WithReason(e.GetUserReason())
+	}
+}
+
+// WithUserReason sets the user-facing reason.
+// If the reason is a TemplateReason, the user-facing reason is used as its template.
+func WithUserReason(reason string) Option {
+	return func(e *StructuredError) {
+		switch r := e.reason.(type) {
+		case *DefaultReason:
+			e.reason = r.WithReason(reason)
+		case *TemplateReason:
+			e.reason = r.WithReason(reason)
+		default:
+			// Otherwise create a DefaultReason with the same code and message
+			e.reason = NewDefaultReason(e.GetCode(), e.GetMessage()).WithReason(reason)
+		}
+	}
+}
+
+// WithSeverity sets the severity.
+func WithSeverity(severity Severity) Option {
+	return func(e *StructuredError) {
+		e.severity = severity
+	}
+}
+
+// WithDocsURL sets the link to the documentation of the error.
+func WithDocsURL(url string) Option {
+	return func(e *StructuredError) {
+		e.docsURL = url
+	}
+}
+
+// WithRetryability sets whether the failed operation can be retried.
+func WithRetryability(retry Retryability) Option {
+	return func(e *StructuredError) {
+		e.retry = retry
+		if retry != RetryableAfterDelay {
+			e.retryDelay = 0
+		}
+	}
+}
+
+// WithRetryDelay marks the error as retryable after the given delay.
+func WithRetryDelay(delay time.Duration) Option {
+	return func(e *StructuredError) {
+		e.retry = RetryableAfterDelay
+		e.retryDelay = delay
+	}
+}
+
+// WithCustomReason sets the Reason, which provides the code, message and user reason.
+func WithCustomReason(reason Reason) Option {
+	return func(e *StructuredError) {
+		e.reason = reason
+	}
+}
+
+// WithMetadata adds a metadata entry.
+// The value is converted with AnyValue, so numbers, booleans, times, slices and maps keep their type.
+func WithMetadata(key string, value any) Option {
+	return func(e *StructuredError) {
+		e.setMetadata(key, AnyValue(value))
+	}
+}
+
+// WithErrorInfo sets the domain and adds string metadata, both reported in the gRPC ErrorInfo.
+func WithErrorInfo(domain string, metadata map[string]string) Option {
+	return func(e *StructuredError) {
+		e.Domain = domain
+		for k, v := range metadata {
+			e.setMetadata(k, StringValue(v))
+		}
+	}
+}
+
+// WithBadRequest adds field violations, reported in a gRPC BadRequest detail.
+func WithBadRequest(fieldViolations map[string]string) Option {
+	return func(e *StructuredError) {
+		// Field violations are stored in metadata with a special prefix
+		for field, description := range fieldViolations {
+			e.setMetadata("field:"+field, StringValue(description))
+		}
+	}
+}
+
+// WithPreconditionFailure adds precondition violations, reported in a gRPC PreconditionFailure detail.
+func WithPreconditionFailure(violations map[string]string) Option {
+	return func(e *StructuredError) {
+		// Precondition violations are stored in metadata with a special prefix
+		for condition, description := range violations {
+			e.setMetadata("precondition:"+condition, StringValue(description))
+		}
+	}
+}
+
+// WithRedaction adds a policy that decides which metadata entries are sent to clients.
+// It is consulted before the policies added earlier, such as those of a factory,
+// and before the DefaultRedactionPolicy, see RedactionPolicy.
+func WithRedaction(policy *RedactionPolicy) Option {
+	return func(e *StructuredError) {
+		e.redaction = append(slices.Clip(e.redaction), policy)
+	}
+}
+
+// WithCause sets the error that caused the error, reachable through Unwrap and GetCause.
+// It has no effect on definitions, whose errors get their cause from Definition.Wrap.
+func WithCause(cause error) Option {
+	return func(e *StructuredError) {
+		e.Cause = cause
+	}
+}
+
+// WithStackMode captures the call stack with the given mode instead of DefaultStackMode.
+// Passed to Define, it sets the stack capture mode of every error created from the Definition.
+func WithStackMode(mode StackMode) Option {
+	return func(e *StructuredError) {
+		e.stackMode = &mode
+		e.stack = captureStack(mode)
+	}
+}
@@ -0,0 +1,114 @@
+package xerr
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	cause := errors.New("connection reset")
+	err := New("USER_NOT_FOUND", "user not found",
+		WithHTTPStatus(http.StatusNotFound),
+		WithGRPCStatus(codes.NotFound),
+		WithUserReason("We could not find your account"),
+		WithDomain("users.example.com"),
+		WithMetadata("user_id", 42),
+		WithBadRequest(map[string]string{"email": "is invalid"}),
+		WithPreconditionFailure(map[string]string{"tos": "not accepted"}),
+		WithSeverity(SeverityWarning),
+		WithRetryDelay(time.Second),
+		WithDocsURL("https://docs.example.com/errors/user-not-found"),
+		WithCause(cause),
+	).(*StructuredError)
+
+	if err.GetCode() != "USER_NOT_FOUND" || err.GetMessage() != "user not found" || err.GetUserReason() != "We could not find your account" {
+		t.Fatalf("unexpected reason: %v", err.GetReason())
+	}
+	if err.GetHTTPCode() != http.StatusNotFound || err.GetGRPCCode() != codes.NotFound {
+		t.Fatalf("unexpected status codes: %d/%s", err.GetHTTPCode(), err.GetGRPCCode())
+	}
+	if v, _ := err.GetMetadataValue("user_id"); v.Kind() != KindInt {
+		t.Fatalf("expected typed metadata, got %v", v)
+	}
+	if len(err.GetBadRequest().GetFieldViolations()) != 1 || len(err.GetPreconditionFailure().GetViolations()) != 1 {
+		t.Fatal("expected BadRequest and PreconditionFailure details")
+	}
+	if err.GetErrorInfo().GetDomain() != "users.example.com" || err.GetSeverity() != SeverityWarning {
+		t.Fatal("expected the domain and severity to be set")
+	}
+	if RetryDelay(err) != time.Second || err.GetDocsURL() == "" {
+		t.Fatal("expected the retry delay and docs URL to be set")
+	}
+	if !errors.Is(err, cause) || err.GetCause() != cause {
+		t.Fatal("expected the cause to be set")
+	}
+}
+
+func TestNewOptionsOrder(t *testing.T) {
+	err := New("CODE", "msg",
+		WithErrorInfo("a.example.com", map[string]string{"k": "v"}),
+		WithDomain("b.example.com"),
+		WithCustomReason(NewTemplateReason("CUSTOM", "hello {name}", Params{"name": "Ada"})),
+		WithUserReason("Hi {name}"),
+	)
+	if got := err.(*StructuredError).Domain; got != "b.example.com" {
+		t.Fatalf("expected later options to win, got %q", got)
+	}
+	if err.GetCode() != "CUSTOM" || err.GetMessage() != "hello Ada" || err.GetUserReason() != "Hi Ada" {
+		t.Fatalf("expected the user reason to be a template of the custom reason, got %q/%q/%q",
+			err.GetCode(), err.GetMessage(), err.GetUserReason())
+	}
+	if err.GetMetadata()["k"] != "v" {
+		t.Fatal("expected ErrorInfo metadata")
+	}
+}
+
+func TestOptionsAreReusable(t *testing.T) {
+	notFound := WithOptions(
+		WithHTTPStatus(http.StatusNotFound),
+		WithGRPCStatus(codes.NotFound),
+		WithSeverity(SeverityInfo),
+	)
+
+	def := NewDefinition("TEST_OPTIONS_REUSE", notFound, WithMessage("item {id} not found"))
+	fromDef := def.New(Params{"id": 7})
+	fromNew := New("TEST_OPTIONS_REUSE", "item 7 not found", notFound)
+	fromWrap := Wrap(errors.New("no rows"), "TEST_OPTIONS_REUSE", notFound)
+	f := NewFactory("items.example.com", WithDefaultOptions(notFound, WithMetadata("service", "items")))
+	fromFactory := f.New("TEST_OPTIONS_REUSE", "item 7 not found", WithSeverity(SeverityError))
+
+	for name, err := range map[string]Error{"definition": fromDef, "New": fromNew, "Wrap": fromWrap, "factory": fromFactory} {
+		if err.GetHTTPCode() != http.StatusNotFound || err.GetGRPCCode() != codes.NotFound {
+			t.Errorf("%s: expected the shared status codes, got %d/%s", name, err.GetHTTPCode(), err.GetGRPCCode())
+		}
+	}
+	if fromFactory.GetSeverity() != SeverityError || fromFactory.GetMetadata()["service"] != "items" {
+		t.Fatal("expected factory default options to apply before the call options")
+	}
+	if fromDef.GetSeverity() != SeverityInfo || fromDef.GetMessage() != "item 7 not found" {
+		t.Fatalf("unexpected definition error: %v", fromDef)
+	}
+}
+
+func TestWithStackMode(t *testing.T) {
+	withStackMode(t, StackOff)
+
+	err := New("CODE", "msg", WithStackMode(StackCaller)).(*StructuredError)
+	if origin, ok := err.Origin(); !ok || !strings.HasSuffix(origin.File(), "options_test.go") {
+		t.Fatalf("expected the caller to be captured, got %v", err.StackTrace())
+	}
+
+	def := NewDefinition("TEST_STACK_MODE", WithStackMode(StackCaller))
+	err = def.New().(*StructuredError)
+	if origin, ok := err.Origin(); !ok || !strings.HasSuffix(origin.File(), "options_test.go") {
+		t.Fatalf("expected the definition stack mode to apply to its errors, got %v", err.StackTrace())
+	}
+	if NewDefinition("TEST_STACK_MODE").New().(*StructuredError).StackTrace() != nil {
+		t.Fatal("expected no stack with the default mode")
+	}
+}
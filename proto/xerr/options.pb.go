@@ -0,0 +1,295 @@
+// Options to declare xerr error definitions on protobuf enums.
+//
+// Annotate an enum, typically named ErrorReason, and generate Go definitions with
+// protoc-gen-go-xerr. Every value becomes an error whose code, and thus the
+// ErrorInfo reason, is the value name:
+//
+//   import "xerr/options.proto";
+//
+//   option (xerr.domain) = "users.example.com";
+//
+//   enum ErrorReason {
+//     option (xerr.defaults) = { http: 400 grpc: "INVALID_ARGUMENT" };
+//
+//     ERROR_REASON_UNSPECIFIED = 0;
+//     // The user does not exist.
+//     USER_NOT_FOUND = 1 [(xerr.error) = { http: 404 grpc: "NOT_FOUND" message: "user {user_id} not found" }];
+//     EMAIL_INVALID = 2;
+//   }
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: xerr/options.proto
+
+package xerrpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ErrorOptions describes an error definition. The fields match those of an xerr
+// catalog entry; unset fields default to those of the enum, then to those of the
+// code in the xerr code catalog.
+type ErrorOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// HTTP status code, such as 404.
+	Http int32 `protobuf:"varint,1,opt,name=http,proto3" json:"http,omitempty"`
+	// gRPC status code, such as NOT_FOUND or NotFound.
+	Grpc string `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
+	// Developer-facing message template, with {name} placeholders.
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// User-facing reason template, with {name} placeholders.
+	Reason string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Go types of the template parameters, string by default.
+	Params map[string]string `protobuf:"bytes,5,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Retry classification: retryable, not_retryable or retry_after_delay.
+	Retry string `protobuf:"bytes,6,opt,name=retry,proto3" json:"retry,omitempty"`
+	// Minimum delay before retrying, such as 30s.
+	RetryDelay string `protobuf:"bytes,7,opt,name=retry_delay,json=retryDelay,proto3" json:"retry_delay,omitempty"`
+	// Severity: debug, info, warning, error or critical.
+	Severity string `protobuf:"bytes,8,opt,name=severity,proto3" json:"severity,omitempty"`
+	// Link to the documentation of the error.
+	DocsUrl       string `protobuf:"bytes,9,opt,name=docs_url,json=docsUrl,proto3" json:"docs_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorOptions) Reset() {
+	*x = ErrorOptions{}
+	mi := &file_xerr_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorOptions) ProtoMessage() {}
+
+func (x *ErrorOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_xerr_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorOptions.ProtoReflect.Descriptor instead.
+func (*ErrorOptions) Descriptor() ([]byte, []int) {
+	return file_xerr_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ErrorOptions) GetHttp() int32 {
+	if x != nil {
+		return x.Http
+	}
+	return 0
+}
+
+func (x *ErrorOptions) GetGrpc() string {
+	if x != nil {
+		return x.Grpc
+	}
+	return ""
+}
+
+func (x *ErrorOptions) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorOptions) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ErrorOptions) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *ErrorOptions) GetRetry() string {
+	if x != nil {
+		return x.Retry
+	}
+	return ""
+}
+
+func (x *ErrorOptions) GetRetryDelay() string {
+	if x != nil {
+		return x.RetryDelay
+	}
+	return ""
+}
+
+func (x *ErrorOptions) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *ErrorOptions) GetDocsUrl() string {
+	if x != nil {
+		return x.DocsUrl
+	}
+	return ""
+}
+
+var file_xerr_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.FileOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52710,
+		Name:          "xerr.domain",
+		Tag:           "bytes,52710,opt,name=domain",
+		Filename:      "xerr/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.EnumOptions)(nil),
+		ExtensionType: (*ErrorOptions)(nil),
+		Field:         52710,
+		Name:          "xerr.defaults",
+		Tag:           "bytes,52710,opt,name=defaults",
+		Filename:      "xerr/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.EnumValueOptions)(nil),
+		ExtensionType: (*ErrorOptions)(nil),
+		Field:         52710,
+		Name:          "xerr.error",
+		Tag:           "bytes,52710,opt,name=error",
+		Filename:      "xerr/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.FileOptions.
+var (
+	// Domain of the errors declared in the file.
+	//
+	// optional string domain = 52710;
+	E_Domain = &file_xerr_options_proto_extTypes[0]
+)
+
+// Extension fields to descriptorpb.EnumOptions.
+var (
+	// Marks the enum as a list of errors and sets the defaults of its values.
+	//
+	// optional xerr.ErrorOptions defaults = 52710;
+	E_Defaults = &file_xerr_options_proto_extTypes[1]
+)
+
+// Extension fields to descriptorpb.EnumValueOptions.
+var (
+	// Declares the enum value as an error.
+	//
+	// optional xerr.ErrorOptions error = 52710;
+	E_Error = &file_xerr_options_proto_extTypes[2]
+)
+
+var File_xerr_options_proto protoreflect.FileDescriptor
+
+const file_xerr_options_proto_rawDesc = "" +
+	"\n" +
+	"\x12xerr/options.proto\x12\x04xerr\x1a google/protobuf/descriptor.proto\"\xc9\x02\n" +
+	"\fErrorOptions\x12\x12\n" +
+	"\x04http\x18\x01 \x01(\x05R\x04http\x12\x12\n" +
+	"\x04grpc\x18\x02 \x01(\tR\x04grpc\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x126\n" +
+	"\x06params\x18\x05 \x03(\v2\x1e.xerr.ErrorOptions.ParamsEntryR\x06params\x12\x14\n" +
+	"\x05retry\x18\x06 \x01(\tR\x05retry\x12\x1f\n" +
+	"\vretry_delay\x18\a \x01(\tR\n" +
+	"retryDelay\x12\x1a\n" +
+	"\bseverity\x18\b \x01(\tR\bseverity\x12\x19\n" +
+	"\bdocs_url\x18\t \x01(\tR\adocsUrl\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01:6\n" +
+	"\x06domain\x12\x1c.google.protobuf.FileOptions\x18\xe6\x9b\x03 \x01(\tR\x06domain:N\n" +
+	"\bdefaults\x12\x1c.google.protobuf.EnumOptions\x18\xe6\x9b\x03 \x01(\v2\x12.xerr.ErrorOptionsR\bdefaults:M\n" +
+	"\x05error\x12!.google.protobuf.EnumValueOptions\x18\xe6\x9b\x03 \x01(\v2\x12.xerr.ErrorOptionsR\x05errorB+Z)github.com/nduyhai/xerr/proto/xerr;xerrpbb\x06proto3"
+
+var (
+	file_xerr_options_proto_rawDescOnce sync.Once
+	file_xerr_options_proto_rawDescData []byte
+)
+
+func file_xerr_options_proto_rawDescGZIP() []byte {
+	file_xerr_options_proto_rawDescOnce.Do(func() {
+		file_xerr_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_xerr_options_proto_rawDesc), len(file_xerr_options_proto_rawDesc)))
+	})
+	return file_xerr_options_proto_rawDescData
+}
+
+var file_xerr_options_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_xerr_options_proto_goTypes = []any{
+	(*ErrorOptions)(nil),                  // 0: xerr.ErrorOptions
+	nil,                                   // 1: xerr.ErrorOptions.ParamsEntry
+	(*descriptorpb.FileOptions)(nil),      // 2: google.protobuf.FileOptions
+	(*descriptorpb.EnumOptions)(nil),      // 3: google.protobuf.EnumOptions
+	(*descriptorpb.EnumValueOptions)(nil), // 4: google.protobuf.EnumValueOptions
+}
+var file_xerr_options_proto_depIdxs = []int32{
+	1, // 0: xerr.ErrorOptions.params:type_name -> xerr.ErrorOptions.ParamsEntry
+	2, // 1: xerr.domain:extendee -> google.protobuf.FileOptions
+	3, // 2: xerr.defaults:extendee -> google.protobuf.EnumOptions
+	4, // 3: xerr.error:extendee -> google.protobuf.EnumValueOptions
+	0, // 4: xerr.defaults:type_name -> xerr.ErrorOptions
+	0, // 5: xerr.error:type_name -> xerr.ErrorOptions
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	4, // [4:6] is the sub-list for extension type_name
+	1, // [1:4] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_xerr_options_proto_init() }
+func file_xerr_options_proto_init() {
+	if File_xerr_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_xerr_options_proto_rawDesc), len(file_xerr_options_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 3,
+			NumServices:   0,
+		},
+		GoTypes:           file_xerr_options_proto_goTypes,
+		DependencyIndexes: file_xerr_options_proto_depIdxs,
+		MessageInfos:      file_xerr_options_proto_msgTypes,
+		ExtensionInfos:    file_xerr_options_proto_extTypes,
+	}.Build()
+	File_xerr_options_proto = out.File
+	file_xerr_options_proto_goTypes = nil
+	file_xerr_options_proto_depIdxs = nil
+}
@@ -2,6 +2,7 @@ package xerr
 
 // DefaultReason is the default implementation of the Reason interface.
 // It provides a simple struct-based implementation of the Reason interface.
+// A DefaultReason is immutable once created; WithReason returns a modified copy.
 type DefaultReason struct {
 	code    string
 	message string
@@ -31,8 +32,9 @@ func (r *DefaultReason) Reason() string {
 	return r.reason
 }
 
-// WithReason adds a user-friendly reason to the DefaultReason.
+// WithReason returns a copy of the DefaultReason with a user-friendly reason.
 func (r *DefaultReason) WithReason(reason string) *DefaultReason {
-	r.reason = reason
-	return r
+	c := *r
+	c.reason = reason
+	return &c
 }
@@ -0,0 +1,220 @@
+package xerr
+
+import (
+	"strings"
+)
+
+// Params holds the named parameters of a message template.
+type Params map[string]any
+
+// ParamsReason is implemented by Reason implementations that carry structured parameters.
+// The parameters of the reason of a StructuredError are exported into its metadata,
+// and therefore into HTTP responses and gRPC ErrorInfo details.
+type ParamsReason interface {
+	Reason
+
+	// Params returns the parameters of the reason.
+	Params() map[string]Value
+}
+
+// Localizer is implemented by Reason implementations that can render their
+// user-facing reason for a specific locale.
+type Localizer interface {
+	// Localize returns the user-facing reason for the locale, such as "fr" or "fr-CA".
+	Localize(locale string) string
+
+	// Locales returns the locales the reason has translations for.
+	Locales() []string
+}
+
+// TemplateReason is a Reason whose message and user reason are templates with named
+// parameters, such as "user {user_id} not found". The parameters are kept as structured
+// values next to the rendered text, and the user reason can be rendered per locale.
+//
+// In templates, {name} is replaced with the string encoding of the parameter name;
+// placeholders without a matching parameter are left as is, and "{{" and "}}" stand for
+// literal braces.
+// A TemplateReason is immutable once created; the With* methods return a modified copy.
+type TemplateReason struct {
+	code         string
+	message      string
+	reason       string
+	params       map[string]Value
+	translations map[string]string
+}
+
+// NewTemplateReason creates a TemplateReason with the given code, message template and parameters.
+func NewTemplateReason(code string, message string, params Params) *TemplateReason {
+	r := &TemplateReason{code: code, message: message}
+	for k, v := range params {
+		if r.params == nil {
+			r.params = make(map[string]Value, len(params))
+		}
+		r.params[k] = AnyValue(v)
+	}
+	return r
+}
+
+// Code returns the machine-readable error code.
+func (r *TemplateReason) Code() string {
+	return r.code
+}
+
+// Message returns the developer-facing message rendered with the parameters.
+func (r *TemplateReason) Message() string {
+	return RenderTemplate(r.message, r.params)
+}
+
+// Reason returns the user-facing reason rendered with the parameters.
+func (r *TemplateReason) Reason() string {
+	return RenderTemplate(r.reason, r.params)
+}
+
+// MessageTemplate returns the unrendered message template.
+func (r *TemplateReason) MessageTemplate() string {
+	return r.message
+}
+
+// ReasonTemplate returns the unrendered user reason template.
+func (r *TemplateReason) ReasonTemplate() string {
+	return r.reason
+}
+
+// Params returns a copy of the parameters.
+func (r *TemplateReason) Params() map[string]Value {
+	return copyMetadata(r.params)
+}
+
+// Localize returns the user-facing reason for the locale rendered with the parameters.
+// It falls back from a regional locale such as "fr-CA" to its language "fr",
+// and then to the default user reason.
+func (r *TemplateReason) Localize(locale string) string {
+	if template, ok := r.translation(locale); ok {
+		return RenderTemplate(template, r.params)
+	}
+	return r.Reason()
+}
+
+// Locales returns the locales the reason has translations for.
+func (r *TemplateReason) Locales() []string {
+	locales := make([]string, 0, len(r.translations))
+	for locale := range r.translations {
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+// translation returns the user reason template for the locale or its language.
+func (r *TemplateReason) translation(locale string) (string, bool) {
+	for locale != "" {
+		if template, ok := r.translations[locale]; ok {
+			return template, true
+		}
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return "", false
+}
+
+// clone returns a copy of the reason with its own parameters and translations.
+func (r *TemplateReason) clone() *TemplateReason {
+	c := *r
+	c.params = copyMetadata(r.params)
+	if r.translations != nil {
+		c.translations = make(map[string]string, len(r.translations))
+		for locale, template := range r.translations {
+			c.translations[locale] = template
+		}
+	}
+	return &c
+}
+
+// WithReason returns a copy of the reason with the given user reason template.
+func (r *TemplateReason) WithReason(template string) *TemplateReason {
+	c := r.clone()
+	c.reason = template
+	return c
+}
+
+// WithParam returns a copy of the reason with the given parameter set.
+func (r *TemplateReason) WithParam(key string, value any) *TemplateReason {
+	c := r.clone()
+	if c.params == nil {
+		c.params = make(map[string]Value)
+	}
+	c.params[key] = AnyValue(value)
+	return c
+}
+
+// WithTranslation returns a copy of the reason with a user reason template for the locale.
+func (r *TemplateReason) WithTranslation(locale string, template string) *TemplateReason {
+	c := r.clone()
+	if c.translations == nil {
+		c.translations = make(map[string]string)
+	}
+	c.translations[locale] = template
+	return c
+}
+
+// RenderTemplate replaces the {name} placeholders of template with the string encoding
+// of the matching parameters. Placeholders without a matching parameter are left as is,
+// and "{{" and "}}" are rendered as literal braces.
+func RenderTemplate(template string, params map[string]Value) string {
+	if !strings.ContainsAny(template, "{}") {
+		return template
+	}
+
+	var b strings.Builder
+	b.Grow(len(template))
+	for i := 0; i < len(template); {
+		switch {
+		case strings.HasPrefix(template[i:], "{{"):
+			b.WriteByte('{')
+			i += 2
+			continue
+		case strings.HasPrefix(template[i:], "}}"):
+			b.WriteByte('}')
+			i += 2
+			continue
+		case template[i] == '{':
+			if end := strings.IndexByte(template[i+1:], '}'); end >= 0 {
+				if v, ok := params[template[i+1:i+1+end]]; ok {
+					b.WriteString(v.String())
+					i += end + 2
+					continue
+				}
+			}
+		}
+		b.WriteByte(template[i])
+		i++
+	}
+	return b.String()
+}
+
+// TemplateParams returns the names of the {name} placeholders of template, in order of appearance.
+func TemplateParams(template string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for i := 0; i < len(template); i++ {
+		if strings.HasPrefix(template[i:], "{{") || strings.HasPrefix(template[i:], "}}") {
+			i++
+			continue
+		}
+		if template[i] != '{' {
+			continue
+		}
+		end := strings.IndexByte(template[i+1:], '}')
+		if end < 0 {
+			break
+		}
+		if name := template[i+1 : i+1+end]; name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+		i += end + 1
+	}
+	return names
+}
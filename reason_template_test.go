@@ -0,0 +1,149 @@
+package xerr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	params := map[string]Value{"user_id": IntValue(42), "name": StringValue("alice")}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"user {user_id} not found", "user 42 not found"},
+		{"{name} ({user_id})", "alice (42)"},
+		{"missing {unknown}", "missing {unknown}"},
+		{"literal {{user_id}}", "literal {user_id}"},
+		{"unterminated {user_id", "unterminated {user_id"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := RenderTemplate(tt.template, params); got != tt.want {
+			t.Errorf("RenderTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateParams(t *testing.T) {
+	got := TemplateParams("user {user_id} in {org} ({user_id}) {{literal}}")
+	if len(got) != 2 || got[0] != "user_id" || got[1] != "org" {
+		t.Fatalf("unexpected params %v", got)
+	}
+}
+
+func TestTemplateReason(t *testing.T) {
+	reason := NewTemplateReason("USER_NOT_FOUND", "user {user_id} not found", Params{"user_id": 42}).
+		WithReason("We could not find user {user_id}").
+		WithTranslation("fr", "Utilisateur {user_id} introuvable")
+
+	if reason.Message() != "user 42 not found" || reason.Reason() != "We could not find user 42" {
+		t.Fatalf("unexpected rendering %q / %q", reason.Message(), reason.Reason())
+	}
+	if reason.Localize("fr-CA") != "Utilisateur 42 introuvable" {
+		t.Fatalf("expected fallback to language, got %q", reason.Localize("fr-CA"))
+	}
+	if reason.Localize("de") != "We could not find user 42" {
+		t.Fatalf("expected fallback to default reason, got %q", reason.Localize("de"))
+	}
+	if reason.MessageTemplate() != "user {user_id} not found" {
+		t.Fatalf("unexpected template %q", reason.MessageTemplate())
+	}
+	if n, _ := reason.Params()["user_id"].Int64(); n != 42 {
+		t.Fatalf("expected raw param 42, got %v", reason.Params())
+	}
+}
+
+func TestTemplateReasonParamsExported(t *testing.T) {
+	withExposurePolicy(t, nil, false)
+	err := New("USER_NOT_FOUND", "").(*StructuredError).
+		WithCustomReason(NewTemplateReason("USER_NOT_FOUND", "user {user_id} not found", Params{"user_id": 42})).
+		WithMetadata("request_id", "req-1").(*StructuredError)
+
+	if err.GetMessage() != "user 42 not found" {
+		t.Fatalf("unexpected message %q", err.GetMessage())
+	}
+	if err.GetMetadata()["user_id"] != "42" || err.GetMetadata()["request_id"] != "req-1" {
+		t.Fatalf("expected params in metadata, got %v", err.GetMetadata())
+	}
+	if err.GetErrorInfo().Metadata["user_id"] != "42" {
+		t.Fatalf("expected params in ErrorInfo, got %v", err.GetErrorInfo().Metadata)
+	}
+
+	body, _ := err.ToHTTPJSON()
+	var httpErr struct {
+		Message  string         `json:"message"`
+		Metadata map[string]any `json:"metadata"`
+	}
+	if e := json.Unmarshal(body, &httpErr); e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+	if httpErr.Message != "user 42 not found" || httpErr.Metadata["user_id"] != float64(42) {
+		t.Fatalf("expected rendered text and raw params, got %s", body)
+	}
+}
+
+func TestTemplateReasonGRPCLocales(t *testing.T) {
+	reason := NewTemplateReason("USER_NOT_FOUND", "user {user_id} not found", Params{"user_id": 42}).
+		WithReason("User {user_id} not found").
+		WithTranslation("fr", "Utilisateur {user_id} introuvable")
+	err := New("USER_NOT_FOUND", "").(*StructuredError).WithCustomReason(reason).(*StructuredError)
+
+	if err.LocalizedReason("fr") != "Utilisateur 42 introuvable" {
+		t.Fatalf("unexpected localized reason %q", err.LocalizedReason("fr"))
+	}
+
+	locales := map[string]string{}
+	for _, detail := range err.ToGRPCStatus().Details() {
+		if lm, ok := detail.(*errdetails.LocalizedMessage); ok {
+			locales[lm.Locale] = lm.Message
+		}
+	}
+	if locales["en-US"] != "User 42 not found" || locales["fr"] != "Utilisateur 42 introuvable" {
+		t.Fatalf("unexpected localized messages %v", locales)
+	}
+
+	decoded := FromGRPCStatus(err.ToGRPCStatus()).(*StructuredError)
+	if decoded.GetUserReason() != "User 42 not found" || decoded.LocalizedReason("fr-CA") != "Utilisateur 42 introuvable" {
+		t.Fatalf("unexpected decoded reasons %q / %q", decoded.GetUserReason(), decoded.LocalizedReason("fr-CA"))
+	}
+}
+
+func TestFromGRPCStatusLocalizedMessages(t *testing.T) {
+	st, _ := status.New(codes.NotFound, "user {id} not found").WithDetails(
+		&errdetails.LocalizedMessage{Locale: "fr", Message: "Utilisateur {id} introuvable"},
+		&errdetails.LocalizedMessage{Locale: "en-US", Message: "User {id} not found"},
+	)
+	decoded := FromGRPCStatus(st).(*StructuredError)
+
+	if decoded.GetUserReason() != "User {id} not found" || decoded.GetMessage() != "user {id} not found" {
+		t.Fatalf("expected the en-US message as the user reason, got %q / %q", decoded.GetUserReason(), decoded.GetMessage())
+	}
+	if decoded.LocalizedReason("fr") != "Utilisateur {id} introuvable" || decoded.LocalizedReason("de") != "User {id} not found" {
+		t.Fatalf("unexpected translations %q / %q", decoded.LocalizedReason("fr"), decoded.LocalizedReason("de"))
+	}
+}
+
+func TestDefinitionWithParams(t *testing.T) {
+	def := NewDefinition("TEST_ORDER_NOT_FOUND",
+		WithMessage("order {order_id} not found"),
+		WithUserReason("We could not find order {order_id}"),
+	)
+	err := def.New(Params{"order_id": "o-1"})
+
+	if err.GetMessage() != "order o-1 not found" || err.GetUserReason() != "We could not find order o-1" {
+		t.Fatalf("unexpected rendering %q / %q", err.GetMessage(), err.GetUserReason())
+	}
+	if v, ok := err.GetMetadataValue("order_id"); !ok || v.String() != "o-1" {
+		t.Fatal("expected order_id in metadata")
+	}
+	if !errors.Is(err, def) {
+		t.Fatal("expected instance to match its definition")
+	}
+}
@@ -0,0 +1,172 @@
+package xerr
+
+import (
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Visibility classifies who may see a metadata entry.
+type Visibility int
+
+const (
+	// VisibilityPublic entries are sent to clients over HTTP and gRPC.
+	VisibilityPublic Visibility = iota
+	// VisibilityInternal entries are dropped or masked at transport boundaries,
+	// but are still recorded by LogValue and the %+v format.
+	VisibilityInternal
+	// VisibilitySecret entries are dropped or masked at transport boundaries and masked
+	// in LogValue and the %+v format. They are only available through the metadata accessors.
+	VisibilitySecret
+)
+
+// String returns the name of the visibility.
+func (v Visibility) String() string {
+	switch v {
+	case VisibilityPublic:
+		return "public"
+	case VisibilityInternal:
+		return "internal"
+	case VisibilitySecret:
+		return "secret"
+	default:
+		return "Visibility(" + strconv.Itoa(int(v)) + ")"
+	}
+}
+
+// RedactedValue replaces secret metadata values in logs when the policy has no Mask.
+const RedactedValue = "[REDACTED]"
+
+// RedactionRule assigns a visibility to the metadata keys that match Pattern.
+// Pattern is either an exact key, such as "sql", a prefix followed by a single trailing "*",
+// such as "upstream_*", or a glob as accepted by path.Match, such as "user_*email".
+type RedactionRule struct {
+	Pattern    string
+	Visibility Visibility
+}
+
+// prefix returns the prefix of a prefix pattern.
+// The second return value reports whether the pattern is a prefix pattern.
+func (r RedactionRule) prefix() (string, bool) {
+	prefix, ok := strings.CutSuffix(r.Pattern, "*")
+	return prefix, ok && !strings.ContainsAny(prefix, `*?[\`)
+}
+
+// RedactionPolicy decides which metadata entries of an error are sent to clients.
+//
+// The visibility of a key is set by the most specific rule that matches it: an exact key,
+// then the longest prefix, then the first matching glob. Keys that no rule matches are
+// public, unless a policy of a broader scope matches them: policies set on an error with
+// WithRedaction are consulted first, the last one first, then the DefaultRedactionPolicy.
+//
+//	xerr.DefaultRedactionPolicy = &xerr.RedactionPolicy{
+//		Rules: []xerr.RedactionRule{
+//			{Pattern: "sql", Visibility: xerr.VisibilityInternal},
+//			{Pattern: "upstream_*", Visibility: xerr.VisibilityInternal},
+//			{Pattern: "user_*email", Visibility: xerr.VisibilitySecret},
+//		},
+//	}
+type RedactionPolicy struct {
+	Rules []RedactionRule // Rules that mark keys as internal or secret, or public again
+	Mask  string          // If set, replaces redacted values instead of dropping the entries
+}
+
+// DefaultRedactionPolicy is the process-wide policy, consulted after the policies of the error.
+// It is nil by default, which makes every metadata entry public.
+var DefaultRedactionPolicy *RedactionPolicy
+
+// Visibility returns the visibility the policy assigns to key, or VisibilityPublic
+// if no rule matches it.
+func (p *RedactionPolicy) Visibility(key string) Visibility {
+	v, _ := p.lookup(key)
+	return v
+}
+
+// lookup returns the visibility of the most specific rule that matches key.
+// The second return value reports whether a rule matched.
+func (p *RedactionPolicy) lookup(key string) (Visibility, bool) {
+	if p == nil {
+		return VisibilityPublic, false
+	}
+	var prefixRule, globRule *RedactionRule
+	for i, rule := range p.Rules {
+		if rule.Pattern == key {
+			return rule.Visibility, true
+		}
+		if prefix, ok := rule.prefix(); ok {
+			if strings.HasPrefix(key, prefix) && (prefixRule == nil || len(rule.Pattern) > len(prefixRule.Pattern)) {
+				prefixRule = &p.Rules[i]
+			}
+		} else if globRule == nil {
+			if matched, _ := path.Match(rule.Pattern, key); matched {
+				globRule = &p.Rules[i]
+			}
+		}
+	}
+	if prefixRule != nil {
+		return prefixRule.Visibility, true
+	}
+	if globRule != nil {
+		return globRule.Visibility, true
+	}
+	return VisibilityPublic, false
+}
+
+// visibility returns the visibility of key under policies, the last one first, and the
+// DefaultRedactionPolicy, with the mask of the policy that decided it.
+func visibility(policies []*RedactionPolicy, key string) (Visibility, string) {
+	for _, p := range slices.Backward(policies) {
+		if v, ok := p.lookup(key); ok {
+			return v, p.Mask
+		}
+	}
+	if v, ok := DefaultRedactionPolicy.lookup(key); ok {
+		return v, DefaultRedactionPolicy.Mask
+	}
+	return VisibilityPublic, ""
+}
+
+// redactionOf returns the policies set on err. A MultiError uses those of its representative error.
+func redactionOf(err Error) []*RedactionPolicy {
+	switch x := err.(type) {
+	case *StructuredError:
+		return x.redaction
+	case *MultiError:
+		return redactionOf(x.Representative())
+	default:
+		return nil
+	}
+}
+
+// publicMetadata removes or masks the entries of metadata that are not public under
+// the policies of err, and returns it. The map must not be shared.
+func publicMetadata(err Error, metadata map[string]Value) map[string]Value {
+	policies := redactionOf(err)
+	for k := range metadata {
+		v, mask := visibility(policies, k)
+		switch {
+		case v == VisibilityPublic:
+		case mask == "":
+			delete(metadata, k)
+		default:
+			metadata[k] = StringValue(mask)
+		}
+	}
+	return metadata
+}
+
+// loggedMetadata masks the secret entries of metadata under the policies of err,
+// and returns it. The map must not be shared.
+func loggedMetadata(err Error, metadata map[string]Value) map[string]Value {
+	policies := redactionOf(err)
+	for k := range metadata {
+		if v, mask := visibility(policies, k); v == VisibilitySecret {
+			if mask == "" {
+				mask = RedactedValue
+			}
+			metadata[k] = StringValue(mask)
+		}
+	}
+	return metadata
+}
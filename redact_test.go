@@ -0,0 +1,173 @@
+package xerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func withRedactionPolicy(t *testing.T, policy *RedactionPolicy) {
+	t.Helper()
+	previous := DefaultRedactionPolicy
+	DefaultRedactionPolicy = policy
+	t.Cleanup(func() { DefaultRedactionPolicy = previous })
+}
+
+func TestRedactionPolicyVisibility(t *testing.T) {
+	p := &RedactionPolicy{Rules: []RedactionRule{
+		{Pattern: "user_*email", Visibility: VisibilitySecret},
+		{Pattern: "upstream_*", Visibility: VisibilityInternal},
+		{Pattern: "upstream_region*", Visibility: VisibilityPublic},
+		{Pattern: "sql", Visibility: VisibilityInternal},
+		{Pattern: "*", Visibility: VisibilityInternal},
+		{Pattern: "user_id", Visibility: VisibilityPublic},
+	}}
+	tests := []struct {
+		key  string
+		want Visibility
+	}{
+		{"sql", VisibilityInternal},
+		{"user_id", VisibilityPublic},
+		{"upstream_host", VisibilityInternal},
+		{"upstream_region", VisibilityPublic},
+		{"user_work_email", VisibilityInternal}, // prefix rules win over globs
+		{"anything", VisibilityInternal},
+	}
+	for _, tt := range tests {
+		if got := p.Visibility(tt.key); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.key, tt.want, got)
+		}
+	}
+
+	globs := &RedactionPolicy{Rules: []RedactionRule{{Pattern: "user_*email", Visibility: VisibilitySecret}}}
+	if globs.Visibility("user_work_email") != VisibilitySecret || globs.Visibility("user_name") != VisibilityPublic {
+		t.Fatal("expected glob rules to match keys")
+	}
+	if (*RedactionPolicy)(nil).Visibility("sql") != VisibilityPublic {
+		t.Fatal("expected a nil policy to make every key public")
+	}
+}
+
+// redactedError returns an error with public, internal and secret metadata.
+func redactedError(opts ...Option) *StructuredError {
+	return New("DB_ERROR", "query failed", append([]Option{
+		WithMetadata("table", "users"),
+		WithMetadata("sql", "SELECT * FROM users"),
+		WithMetadata("user_email", "jane@example.com"),
+	}, opts...)...).(*StructuredError)
+}
+
+var testRedactionPolicy = &RedactionPolicy{Rules: []RedactionRule{
+	{Pattern: "sql", Visibility: VisibilityInternal},
+	{Pattern: "user_*email", Visibility: VisibilitySecret},
+}}
+
+func TestRedactionAtTransports(t *testing.T) {
+	withRedactionPolicy(t, testRedactionPolicy)
+	err := redactedError()
+
+	body, _ := err.ToHTTPJSON()
+	var httpErr HTTPError
+	if err := json.Unmarshal(body, &httpErr); err != nil {
+		t.Fatal(err)
+	}
+	if len(httpErr.Metadata) != 1 || httpErr.Metadata["table"] != "users" {
+		t.Fatalf("expected only public metadata over HTTP, got %v", httpErr.Metadata)
+	}
+
+	if md := err.GetErrorInfo().Metadata; len(md) != 1 || md["table"] != "users" {
+		t.Fatalf("expected only public metadata in the ErrorInfo, got %v", md)
+	}
+	for _, detail := range err.ToGRPCStatus().Details() {
+		if s, ok := detail.(*structpb.Struct); ok && len(s.Fields) != 1 {
+			t.Fatalf("expected only public typed metadata over gRPC, got %v", s.Fields)
+		}
+	}
+
+	// Metadata accessors are not redacted
+	if err.GetMetadata()["sql"] == "" {
+		t.Fatal("expected internal metadata to stay available in the process")
+	}
+
+	// Aggregated errors are redacted with the policies of their representative
+	body, _ = Join(err).(*MultiError).ToHTTPJSON()
+	if strings.Contains(string(body), "SELECT") || strings.Contains(string(body), "jane") {
+		t.Fatalf("expected aggregated errors to be redacted, got %s", body)
+	}
+}
+
+func TestRedactionMask(t *testing.T) {
+	err := redactedError(WithRedaction(&RedactionPolicy{Rules: testRedactionPolicy.Rules, Mask: "***"}))
+	if md := err.GetErrorInfo().Metadata; md["sql"] != "***" || md["user_email"] != "***" || md["table"] != "users" {
+		t.Fatalf("expected masked metadata, got %v", md)
+	}
+}
+
+func TestRedactionOfViolations(t *testing.T) {
+	err := New("INVALID", "bad request",
+		WithBadRequest(map[string]string{"email": "jane@example.com is taken", "name": "is required"}),
+		WithPreconditionFailure(map[string]string{"tos": "not accepted", "quota": "tenant 42 over quota"}),
+		WithRedaction(&RedactionPolicy{Rules: []RedactionRule{
+			{Pattern: "field:email", Visibility: VisibilitySecret},
+			{Pattern: "precondition:quota", Visibility: VisibilityInternal},
+		}}),
+	).(*StructuredError)
+
+	if v := err.GetBadRequest().GetFieldViolations(); len(v) != 1 || v[0].Field != "name" {
+		t.Fatalf("expected only public field violations, got %v", v)
+	}
+	if v := err.GetPreconditionFailure().GetViolations(); len(v) != 1 || v[0].Subject != "tos" {
+		t.Fatalf("expected only public precondition failures, got %v", v)
+	}
+}
+
+func TestRedactionInLogs(t *testing.T) {
+	withRedactionPolicy(t, testRedactionPolicy)
+	err := redactedError()
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("failed", slog.Any("error", err))
+	logged := buf.String()
+	if !strings.Contains(logged, "SELECT * FROM users") {
+		t.Fatalf("expected internal metadata in logs, got %s", logged)
+	}
+	if strings.Contains(logged, "jane@example.com") || !strings.Contains(logged, RedactedValue) {
+		t.Fatalf("expected secret metadata to be masked in logs, got %s", logged)
+	}
+
+	if tree := fmt.Sprintf("%+v", err); strings.Contains(tree, "jane@example.com") || !strings.Contains(tree, "sql: SELECT") {
+		t.Fatalf("expected secret metadata to be masked in the error tree, got %s", tree)
+	}
+}
+
+func TestRedactionScopes(t *testing.T) {
+	withRedactionPolicy(t, testRedactionPolicy)
+	f := newTestFactory(WithDefaultRedaction(&RedactionPolicy{Rules: []RedactionRule{
+		{Pattern: "service", Visibility: VisibilityInternal},
+	}}))
+
+	// The factory policy adds to the global one
+	err := f.New(INTERNAL, "failed", WithMetadata("sql", "SELECT 1"))
+	if md := err.(*StructuredError).GetErrorInfo().Metadata; md["service"] != "" || md["sql"] != "" || md["version"] != "3" {
+		t.Fatalf("expected the factory and global policies to apply, got %v", md)
+	}
+
+	// A policy set on the error takes precedence over both
+	err = f.New(INTERNAL, "failed", WithMetadata("sql", "SELECT 1"), WithRedaction(&RedactionPolicy{Rules: []RedactionRule{
+		{Pattern: "sql", Visibility: VisibilityPublic},
+	}}))
+	if md := err.(*StructuredError).GetErrorInfo().Metadata; md["sql"] != "SELECT 1" || md["service"] != "" {
+		t.Fatalf("expected the error policy to take precedence, got %v", md)
+	}
+
+	// Outer layers inherit the policies with the metadata
+	outer := WrapWithReason(err, NewDefaultReason("OUTER", "outer"))
+	if md := outer.(*StructuredError).GetErrorInfo().Metadata; md["sql"] != "SELECT 1" || md["service"] != "" {
+		t.Fatalf("expected the outer layer to inherit the policies, got %v", md)
+	}
+}
@@ -0,0 +1,181 @@
+package xerr
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Retryability classifies whether the operation that failed with an error can be retried.
+type Retryability int
+
+const (
+	// RetryUnspecified means the classification is derived from the gRPC status code.
+	RetryUnspecified Retryability = iota
+	// NotRetryable means retrying the operation will fail again.
+	NotRetryable
+	// Retryable means the operation can be retried, with the caller's own backoff.
+	Retryable
+	// RetryableAfterDelay means the operation can be retried once the retry delay has passed.
+	RetryableAfterDelay
+)
+
+// String returns the name of the classification.
+func (r Retryability) String() string {
+	switch r {
+	case RetryUnspecified:
+		return "unspecified"
+	case NotRetryable:
+		return "not retryable"
+	case Retryable:
+		return "retryable"
+	case RetryableAfterDelay:
+		return "retryable after delay"
+	default:
+		return "Retryability(" + strconv.Itoa(int(r)) + ")"
+	}
+}
+
+// RetryabilityForGRPCCode returns the default classification of a gRPC status code.
+// Unavailable, DeadlineExceeded and Aborted are retryable; ResourceExhausted is retryable
+// after a delay; every other code is not retryable.
+func RetryabilityForGRPCCode(code codes.Code) Retryability {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return Retryable
+	case codes.ResourceExhausted:
+		return RetryableAfterDelay
+	default:
+		return NotRetryable
+	}
+}
+
+// retryable reports whether the classification allows a retry.
+func (r Retryability) retryable() bool {
+	return r == Retryable || r == RetryableAfterDelay
+}
+
+// IsRetryable reports whether the operation that failed with err can be retried.
+// It inspects the first Error in the chain of err; other errors are not retryable.
+func IsRetryable(err error) bool {
+	var xe Error
+	if !errors.As(err, &xe) {
+		return false
+	}
+	return xe.GetRetryability().retryable()
+}
+
+// RetryDelay returns how long to wait before retrying the operation that failed with err.
+// It is zero if err is not retryable after a delay or carries no delay hint,
+// in which case the caller chooses its own backoff.
+func RetryDelay(err error) time.Duration {
+	var xe Error
+	if !errors.As(err, &xe) {
+		return 0
+	}
+	return xe.GetRetryDelay()
+}
+
+// GetRetryability returns the retry classification of the error.
+// Unless set explicitly, it is derived from the gRPC status code.
+func (e *StructuredError) GetRetryability() Retryability {
+	if e.retry != RetryUnspecified {
+		return e.retry
+	}
+	return RetryabilityForGRPCCode(e.GRPCCode)
+}
+
+// GetRetryDelay returns the delay to wait before retrying, or zero if there is none.
+func (e *StructuredError) GetRetryDelay() time.Duration {
+	if e.GetRetryability() != RetryableAfterDelay {
+		return 0
+	}
+	return e.retryDelay
+}
+
+// WithRetryability returns a copy of the error with the given retry classification.
+func (e *StructuredError) WithRetryability(retry Retryability) Error {
+	c := e.clone()
+	c.retry = retry
+	if retry != RetryableAfterDelay {
+		c.retryDelay = 0
+	}
+	return c
+}
+
+// WithRetryDelay returns a copy of the error that is retryable after the given delay.
+func (e *StructuredError) WithRetryDelay(delay time.Duration) Error {
+	c := e.clone()
+	c.retry = RetryableAfterDelay
+	c.retryDelay = delay
+	return c
+}
+
+// GetRetryability returns the retry classification of the representative error, unless overridden.
+func (m *MultiError) GetRetryability() Retryability {
+	if m.retry != RetryUnspecified {
+		return m.retry
+	}
+	return m.Representative().GetRetryability()
+}
+
+// GetRetryDelay returns the retry delay of the representative error, unless overridden.
+func (m *MultiError) GetRetryDelay() time.Duration {
+	if m.retry == RetryUnspecified {
+		return m.Representative().GetRetryDelay()
+	}
+	if m.retry != RetryableAfterDelay {
+		return 0
+	}
+	return m.retryDelay
+}
+
+// WithRetryability returns a copy of the MultiError with the given retry classification.
+func (m *MultiError) WithRetryability(retry Retryability) Error {
+	c := m.clone()
+	c.retry = retry
+	if retry != RetryableAfterDelay {
+		c.retryDelay = 0
+	}
+	return c
+}
+
+// WithRetryDelay returns a copy of the MultiError that is retryable after the given delay.
+func (m *MultiError) WithRetryDelay(delay time.Duration) Error {
+	c := m.clone()
+	c.retry = RetryableAfterDelay
+	c.retryDelay = delay
+	return c
+}
+
+// retryableKey is the reserved ErrorInfo metadata key that carries the retry classification
+// when it differs from the one derived from the gRPC status code.
+const retryableKey = "xerr_retryable"
+
+// encodeRetry returns the retry hint to send on the wire for err, where the receiver
+// sees the gRPC status code derived. retryable is nil if the receiver derives the same
+// classification from the status code, and delay is zero if there is no retry delay.
+func encodeRetry(err Error, derived codes.Code) (retryable *bool, delay time.Duration) {
+	delay = err.GetRetryDelay()
+	r := err.GetRetryability().retryable()
+	if delay > 0 || r != RetryabilityForGRPCCode(derived).retryable() {
+		retryable = &r
+	}
+	return retryable, delay
+}
+
+// decodeRetry returns the retry classification of a retry hint received on the wire.
+func decodeRetry(retryable *bool, delay time.Duration) Retryability {
+	switch {
+	case delay > 0:
+		return RetryableAfterDelay
+	case retryable == nil:
+		return RetryUnspecified
+	case *retryable:
+		return Retryable
+	default:
+		return NotRetryable
+	}
+}
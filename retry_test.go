@@ -0,0 +1,150 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRetryabilityDefaults(t *testing.T) {
+	tests := []struct {
+		code Code
+		want Retryability
+	}{
+		{UNAVAILABLE, Retryable},
+		{TIMEOUT, Retryable},
+		{ABORTED, Retryable},
+		{RESOURCE_EXHAUSTED, RetryableAfterDelay},
+		{INVALID_ARGUMENT, NotRetryable},
+		{ALREADY_EXISTS, NotRetryable},
+		{INTERNAL, NotRetryable},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			err := NewStandardError(tt.code, "")
+			if got := err.GetRetryability(); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+			if IsRetryable(err) != tt.want.retryable() {
+				t.Fatalf("unexpected IsRetryable for %s", tt.code)
+			}
+		})
+	}
+}
+
+func TestIsRetryableAndRetryDelay(t *testing.T) {
+	base := NewStandardError(INTERNAL, "flaky dependency")
+	err := fmt.Errorf("handler: %w", base.WithRetryDelay(2*time.Second))
+
+	if !IsRetryable(err) || RetryDelay(err) != 2*time.Second {
+		t.Fatalf("expected retryable after 2s, got %v/%s", IsRetryable(err), RetryDelay(err))
+	}
+	if IsRetryable(base) || RetryDelay(base) != 0 {
+		t.Fatal("expected the original error to stay not retryable")
+	}
+	if IsRetryable(errors.New("plain")) || IsRetryable(nil) {
+		t.Fatal("expected plain errors not to be retryable")
+	}
+
+	notRetryable := NewStandardError(UNAVAILABLE, "maintenance").WithRetryDelay(time.Second).WithRetryability(NotRetryable)
+	if IsRetryable(notRetryable) || RetryDelay(notRetryable) != 0 {
+		t.Fatal("expected WithRetryability to clear the retry delay")
+	}
+
+	def := NewDefinition("TEST_RETRY_LOCKED", WithGRPCStatus(codes.FailedPrecondition), WithRetryDelay(time.Minute))
+	if RetryDelay(def.New()) != time.Minute {
+		t.Fatalf("expected definition retry delay, got %s", RetryDelay(def.New()))
+	}
+}
+
+func TestRetryHTTPRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       Error
+		wantRetry bool
+		wantDelay time.Duration
+	}{
+		{"default retryable", NewStandardError(UNAVAILABLE, "down"), true, 0},
+		{"default not retryable", NewStandardError(ALREADY_EXISTS, "duplicate"), false, 0},
+		{"overridden", NewStandardError(UNAVAILABLE, "down").WithRetryability(NotRetryable), false, 0},
+		{"delay", NewStandardError(RESOURCE_EXHAUSTED, "slow down").WithRetryDelay(1500 * time.Millisecond), true, 1500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, status := tt.err.(*StructuredError).ToHTTPJSON()
+			decoded, err := FromHTTPJSON(body, status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if IsRetryable(decoded) != tt.wantRetry || RetryDelay(decoded) != tt.wantDelay {
+				t.Fatalf("expected %v/%s, got %v/%s from %s", tt.wantRetry, tt.wantDelay, IsRetryable(decoded), RetryDelay(decoded), body)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	NewStandardError(RESOURCE_EXHAUSTED, "").WithRetryDelay(1500 * time.Millisecond).(*StructuredError).ToHTTP(w)
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After rounded up to 2, got %q", got)
+	}
+}
+
+func TestRetryGRPCRoundTrip(t *testing.T) {
+	err := NewStandardError(UNAVAILABLE, "maintenance").WithRetryability(NotRetryable).(*StructuredError)
+	decoded := FromGRPCStatus(err.ToGRPCStatus())
+	if IsRetryable(decoded) {
+		t.Fatal("expected overridden classification to survive gRPC")
+	}
+	if _, ok := decoded.GetMetadata()[retryableKey]; ok {
+		t.Fatal("expected the reserved key to be removed from the metadata")
+	}
+
+	err = NewStandardError(RESOURCE_EXHAUSTED, "slow down").WithRetryDelay(3 * time.Second).(*StructuredError)
+	st := err.ToGRPCStatus()
+	var info *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.RetryInfo); ok {
+			info = d
+		}
+	}
+	if info == nil || info.GetRetryDelay().AsDuration() != 3*time.Second {
+		t.Fatalf("expected RetryInfo with 3s delay, got %v", info)
+	}
+	if decoded := FromGRPCStatus(st); RetryDelay(decoded) != 3*time.Second {
+		t.Fatalf("expected retry delay to survive gRPC, got %s", RetryDelay(decoded))
+	}
+
+	for _, detail := range NewStandardError(UNAVAILABLE, "down").(*StructuredError).ToGRPCStatus().Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Metadata[retryableKey] != "" {
+			t.Fatalf("expected no retry key for the default classification, got %v", info.Metadata)
+		}
+	}
+}
+
+func TestMultiErrorRetry(t *testing.T) {
+	err := JoinWithPrecedence(PrecedenceFirst,
+		NewStandardError(UNAVAILABLE, "inventory is down").WithRetryDelay(time.Second),
+		NewStandardError(INVALID_ARGUMENT, "name is required"),
+	)
+	if !IsRetryable(err) || RetryDelay(err) != time.Second {
+		t.Fatal("expected the representative's retry classification")
+	}
+	if IsRetryable(err.WithRetryability(NotRetryable)) {
+		t.Fatal("expected overridden retry classification")
+	}
+
+	decoded := FromGRPCStatus(err.(*MultiError).ToGRPCStatus())
+	if RetryDelay(decoded) != time.Second {
+		t.Fatalf("expected retry delay to survive gRPC, got %s", RetryDelay(decoded))
+	}
+	body, status := err.(*MultiError).ToHTTPJSON()
+	decoded, _ = FromHTTPJSON(body, status)
+	if RetryDelay(decoded) != time.Second {
+		t.Fatalf("expected retry delay to survive HTTP, got %s", RetryDelay(decoded))
+	}
+}
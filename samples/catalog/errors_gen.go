@@ -0,0 +1,106 @@
+// Code generated by xerrgen from errors.yaml. DO NOT EDIT.
+
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nduyhai/xerr"
+	"google.golang.org/grpc/codes"
+)
+
+// Error codes.
+const (
+	// CodePaymentDeclined is the code of ErrPaymentDeclined.
+	CodePaymentDeclined xerr.Code = "PAYMENT.DECLINED"
+	// CodePaymentAmountTooLarge is the code of ErrPaymentAmountTooLarge.
+	CodePaymentAmountTooLarge xerr.Code = "PAYMENT.AMOUNT_TOO_LARGE"
+	// CodePaymentProviderUnavailable is the code of ErrPaymentProviderUnavailable.
+	CodePaymentProviderUnavailable xerr.Code = "PAYMENT.PROVIDER_UNAVAILABLE"
+)
+
+// ErrPaymentDeclined is the definition of the PAYMENT.DECLINED error.
+//
+// Returned when the card issuer declines the payment.
+//
+// Documentation: https://docs.example.com/errors/payment-declined
+var ErrPaymentDeclined = xerr.Define(CodePaymentDeclined,
+	xerr.WithHTTPStatus(402),
+	xerr.WithGRPCStatus(codes.FailedPrecondition),
+	xerr.WithDomain("payments.example.com"),
+	xerr.WithMessage("payment {payment_id} declined by the issuer: {decline_code}"),
+	xerr.WithUserReason("Your card was declined. Please use another payment method."),
+	xerr.WithRetryability(xerr.NotRetryable),
+	xerr.WithSeverity(xerr.SeverityInfo),
+	xerr.WithDocsURL("https://docs.example.com/errors/payment-declined"),
+)
+
+// NewPaymentDeclined creates a PAYMENT.DECLINED error.
+func NewPaymentDeclined(paymentID string, declineCode string) xerr.Error {
+	return ErrPaymentDeclined.New(xerr.Params{"payment_id": paymentID, "decline_code": declineCode})
+}
+
+// WrapPaymentDeclined creates a PAYMENT.DECLINED error caused by err.
+// It returns nil if err is nil.
+func WrapPaymentDeclined(err error, paymentID string, declineCode string) xerr.Error {
+	return ErrPaymentDeclined.Wrap(err, xerr.Params{"payment_id": paymentID, "decline_code": declineCode})
+}
+
+// IsPaymentDeclined reports whether err or any error in its chain is a PAYMENT.DECLINED error.
+func IsPaymentDeclined(err error) bool {
+	return errors.Is(err, ErrPaymentDeclined)
+}
+
+// ErrPaymentAmountTooLarge is the definition of the PAYMENT.AMOUNT_TOO_LARGE error.
+var ErrPaymentAmountTooLarge = xerr.Define(CodePaymentAmountTooLarge,
+	xerr.WithHTTPStatus(400),
+	xerr.WithGRPCStatus(codes.OutOfRange),
+	xerr.WithDomain("payments.example.com"),
+	xerr.WithMessage("amount {amount} exceeds the limit of {limit}"),
+)
+
+// NewPaymentAmountTooLarge creates a PAYMENT.AMOUNT_TOO_LARGE error.
+func NewPaymentAmountTooLarge(amount int64, limit int64) xerr.Error {
+	return ErrPaymentAmountTooLarge.New(xerr.Params{"amount": amount, "limit": limit})
+}
+
+// WrapPaymentAmountTooLarge creates a PAYMENT.AMOUNT_TOO_LARGE error caused by err.
+// It returns nil if err is nil.
+func WrapPaymentAmountTooLarge(err error, amount int64, limit int64) xerr.Error {
+	return ErrPaymentAmountTooLarge.Wrap(err, xerr.Params{"amount": amount, "limit": limit})
+}
+
+// IsPaymentAmountTooLarge reports whether err or any error in its chain is a PAYMENT.AMOUNT_TOO_LARGE error.
+func IsPaymentAmountTooLarge(err error) bool {
+	return errors.Is(err, ErrPaymentAmountTooLarge)
+}
+
+// ErrPaymentProviderUnavailable is the definition of the PAYMENT.PROVIDER_UNAVAILABLE error.
+//
+// Returned when the payment provider cannot be reached.
+// Clients should retry after the advertised delay.
+var ErrPaymentProviderUnavailable = xerr.Define(CodePaymentProviderUnavailable,
+	xerr.WithHTTPStatus(503),
+	xerr.WithGRPCStatus(codes.Unavailable),
+	xerr.WithDomain("payments.example.com"),
+	xerr.WithMessage("payment provider is unavailable"),
+	xerr.WithRetryDelay(30*time.Second),
+	xerr.WithSeverity(xerr.SeverityError),
+)
+
+// NewPaymentProviderUnavailable creates a PAYMENT.PROVIDER_UNAVAILABLE error.
+func NewPaymentProviderUnavailable() xerr.Error {
+	return ErrPaymentProviderUnavailable.New()
+}
+
+// WrapPaymentProviderUnavailable creates a PAYMENT.PROVIDER_UNAVAILABLE error caused by err.
+// It returns nil if err is nil.
+func WrapPaymentProviderUnavailable(err error) xerr.Error {
+	return ErrPaymentProviderUnavailable.Wrap(err)
+}
+
+// IsPaymentProviderUnavailable reports whether err or any error in its chain is a PAYMENT.PROVIDER_UNAVAILABLE error.
+func IsPaymentProviderUnavailable(err error) bool {
+	return errors.Is(err, ErrPaymentProviderUnavailable)
+}
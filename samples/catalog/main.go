@@ -0,0 +1,35 @@
+// Command catalog shows errors generated by xerrgen from a declarative catalog.
+package main
+
+//go:generate go run github.com/nduyhai/xerr/cmd/xerrgen -out errors_gen.go errors.yaml
+//go:generate go run github.com/nduyhai/xerr/cmd/xerrgen -format markdown -title "Payment Errors" -out ERRORS.md errors.yaml
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nduyhai/xerr"
+)
+
+func main() {
+	fmt.Println("Generated Error Catalog Example")
+	fmt.Println("===============================")
+
+	// Constructors take the template parameters as typed arguments
+	err := NewPaymentDeclined("pay_123", "insufficient_funds")
+	fmt.Println("Error:", err)
+	fmt.Println("User reason:", err.GetUserReason())
+	fmt.Println("HTTP status:", err.GetHTTPCode())
+	fmt.Println("Is PAYMENT.DECLINED:", IsPaymentDeclined(fmt.Errorf("checkout: %w", err)))
+
+	// Parameters are also exported as typed metadata
+	amount, _ := NewPaymentAmountTooLarge(150000, 100000).GetMetadataValue("amount")
+	fmt.Println("Amount metadata:", amount, amount.Kind())
+
+	// Wrapping keeps the cause, and the catalog's retry hints are applied
+	err = WrapPaymentProviderUnavailable(io.ErrUnexpectedEOF)
+	fmt.Println("\nWrapped error:", err)
+	fmt.Println("Cause is io.ErrUnexpectedEOF:", errors.Is(err, io.ErrUnexpectedEOF))
+	fmt.Println("Retryable:", xerr.IsRetryable(err), "after", xerr.RetryDelay(err))
+}
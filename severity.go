@@ -0,0 +1,221 @@
+package xerr
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Severity describes how bad an error is.
+// It is used to pick a log level and to select the representative error of a MultiError.
+type Severity int
+
+const (
+	// SeverityUnspecified means the severity is derived from the error code.
+	SeverityUnspecified Severity = iota
+	// SeverityDebug is used for expected conditions that are only interesting while debugging.
+	SeverityDebug
+	// SeverityInfo is used for expected client errors, such as a resource that does not exist.
+	SeverityInfo
+	// SeverityWarning is used for conditions that may need attention, such as rejected credentials.
+	SeverityWarning
+	// SeverityError is used for failures of the service itself.
+	SeverityError
+	// SeverityCritical is used for failures that need immediate attention, such as data loss.
+	SeverityCritical
+)
+
+// String returns the lower-case name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityUnspecified:
+		return "unspecified"
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "Severity(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// ParseSeverity parses the name of a severity, as returned by String.
+// The second return value is false if the name is not a known severity.
+func ParseSeverity(name string) (Severity, bool) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return SeverityDebug, true
+	case "info":
+		return SeverityInfo, true
+	case "warning", "warn":
+		return SeverityWarning, true
+	case "error":
+		return SeverityError, true
+	case "critical":
+		return SeverityCritical, true
+	default:
+		return SeverityUnspecified, false
+	}
+}
+
+// Level returns the slog level matching the severity.
+// SeverityCritical maps to a level above slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelError
+	}
+}
+
+// SeverityForGRPCCode returns the default severity of a gRPC status code.
+// It is used for codes that are not in the catalog.
+func SeverityForGRPCCode(code codes.Code) Severity {
+	switch code {
+	case codes.OK, codes.Canceled:
+		return SeverityDebug
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition,
+		codes.Aborted, codes.OutOfRange:
+		return SeverityInfo
+	case codes.Unauthenticated, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.DeadlineExceeded, codes.Unimplemented:
+		return SeverityWarning
+	case codes.DataLoss:
+		return SeverityCritical
+	default:
+		return SeverityError
+	}
+}
+
+// GetSeverity returns the severity of the error.
+// Unless set explicitly, it is the severity of the code (or its nearest ancestor) in the catalog,
+// or else derived from the gRPC status code.
+func (e *StructuredError) GetSeverity() Severity {
+	if e.severity != SeverityUnspecified {
+		return e.severity
+	}
+	if info, ok := ResolveCode(Code(e.GetCode())); ok && info.Severity != SeverityUnspecified {
+		return info.Severity
+	}
+	return SeverityForGRPCCode(e.GRPCCode)
+}
+
+// WithSeverity returns a copy of the error with the given severity.
+func (e *StructuredError) WithSeverity(severity Severity) Error {
+	c := e.clone()
+	c.severity = severity
+	return c
+}
+
+// GetSeverity returns the severity of the MultiError: the severity set with WithSeverity,
+// or else the highest severity of the aggregated errors.
+func (m *MultiError) GetSeverity() Severity {
+	if m.severity != SeverityUnspecified {
+		return m.severity
+	}
+	severity := SeverityUnspecified
+	for _, err := range m.errs {
+		severity = max(severity, err.GetSeverity())
+	}
+	return severity
+}
+
+// WithSeverity returns a copy of the MultiError with the given severity.
+func (m *MultiError) WithSeverity(severity Severity) Error {
+	c := m.clone()
+	c.severity = severity
+	return c
+}
+
+// responseLogger holds the logger set with SetResponseLogger.
+var responseLogger atomic.Pointer[slog.Logger]
+
+// SetResponseLogger sets the logger of the error responses sent by WriteError, the
+// Write*HTTPError functions and the gRPC server interceptors, which log every error at
+// the level of its severity. Passing nil disables logging, which is the default.
+// The converters ToHTTP, ToHTTPJSON and ToGRPCStatus never log.
+func SetResponseLogger(logger *slog.Logger) {
+	responseLogger.Store(logger)
+}
+
+// ResponseLogger returns the logger set with SetResponseLogger, or nil if there is none.
+func ResponseLogger() *slog.Logger {
+	return responseLogger.Load()
+}
+
+// LogResponse logs err as an error response with the response logger, at the level of its
+// severity. It does nothing if no logger is set. Call it when sending a response with the
+// converters, which don't log.
+func LogResponse(ctx context.Context, err Error) {
+	logger := ResponseLogger()
+	if logger == nil || err == nil {
+		return
+	}
+	logger.LogAttrs(ctx, err.GetSeverity().Level(), "xerr: error response", slog.Any("error", err))
+}
+
+// LogValue implements slog.LogValuer, so that loggers record the error as a group
+// with its code, message, statuses, severity, metadata and cause.
+func (e *StructuredError) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("code", e.GetCode()),
+		slog.String("message", e.GetMessage()),
+		slog.Int("http", e.HTTPCode),
+		slog.String("grpc", e.GRPCCode.String()),
+		slog.String("severity", e.GetSeverity().String()),
+	}
+	if id := e.GetOccurrenceID(); id != "" {
+		attrs = append(attrs, slog.String("id", id))
+	}
+	if e.Domain != "" {
+		attrs = append(attrs, slog.String("domain", e.Domain))
+	}
+	if reason := e.GetUserReason(); reason != "" {
+		attrs = append(attrs, slog.String("reason", reason))
+	}
+	if metadata := loggedMetadata(e, e.allMetadata()); len(metadata) > 0 {
+		group := make([]slog.Attr, 0, len(metadata))
+		for _, k := range sortedKeys(metadata) {
+			group = append(group, slog.Any(k, metadata[k].Any()))
+		}
+		attrs = append(attrs, slog.Attr{Key: "metadata", Value: slog.GroupValue(group...)})
+	}
+	if e.Cause != nil {
+		attrs = append(attrs, slog.String("cause", e.Cause.Error()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer, so that loggers record the MultiError as a group
+// with its code, message, severity and every aggregated error.
+func (m *MultiError) LogValue() slog.Value {
+	errs := make([]slog.Attr, len(m.errs))
+	for i, err := range m.errs {
+		errs[i] = slog.Any(strconv.Itoa(i), err)
+	}
+	return slog.GroupValue(
+		slog.String("code", m.GetCode()),
+		slog.String("message", m.GetMessage()),
+		slog.Int("http", m.GetHTTPCode()),
+		slog.String("grpc", m.GetGRPCCode().String()),
+		slog.String("severity", m.GetSeverity().String()),
+		slog.Attr{Key: "errors", Value: slog.GroupValue(errs...)},
+	)
+}
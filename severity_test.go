@@ -0,0 +1,165 @@
+package xerr
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStandardCodeSeverity(t *testing.T) {
+	tests := []struct {
+		code Code
+		want Severity
+	}{
+		{CANCELLED, SeverityDebug},
+		{NOT_FOUND, SeverityInfo},
+		{INVALID_ARGUMENT, SeverityInfo},
+		{PERMISSION_DENIED, SeverityWarning},
+		{TIMEOUT, SeverityWarning},
+		{INTERNAL, SeverityError},
+		{DATA_LOSS, SeverityCritical},
+		{"NOT_FOUND.USER", SeverityInfo},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			if got := tt.code.Severity(); got != tt.want {
+				t.Fatalf("expected code severity %s, got %s", tt.want, got)
+			}
+			if got := NewStandardError(tt.code, "").GetSeverity(); got != tt.want {
+				t.Fatalf("expected error severity %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSeverityDerivedFromGRPCCode(t *testing.T) {
+	err := NewWithHTTPAndGRPC("PAYMENT_DECLINED", "card declined", 402, codes.FailedPrecondition)
+	if got := err.GetSeverity(); got != SeverityInfo {
+		t.Fatalf("expected severity derived from gRPC code, got %s", got)
+	}
+}
+
+func TestWithSeverity(t *testing.T) {
+	base := NewStandardError(NOT_FOUND, "config missing")
+	err := base.WithSeverity(SeverityCritical)
+
+	if got := err.GetSeverity(); got != SeverityCritical {
+		t.Fatalf("expected overridden severity, got %s", got)
+	}
+	if got := base.GetSeverity(); got != SeverityInfo {
+		t.Fatalf("expected the original error to keep its severity, got %s", got)
+	}
+
+	def := NewDefinition("TEST_SEVERITY_QUOTA", WithGRPCStatus(codes.ResourceExhausted), WithSeverity(SeverityError))
+	if def.Severity() != SeverityError || def.New().GetSeverity() != SeverityError {
+		t.Fatalf("expected definition severity to apply, got %s", def.New().GetSeverity())
+	}
+}
+
+func TestPrecedenceMostSevereUsesSeverity(t *testing.T) {
+	internal := NewStandardError(INTERNAL, "db failure")
+	critical := NewStandardError(NOT_FOUND, "ledger entry missing").WithSeverity(SeverityCritical)
+
+	err := JoinWithPrecedence(PrecedenceMostSevere, internal, critical)
+	if err.GetCode() != "NOT_FOUND" {
+		t.Fatalf("expected the critical error to be representative, got %s", err.GetCode())
+	}
+	if err.GetSeverity() != SeverityCritical {
+		t.Fatalf("expected the highest severity, got %s", err.GetSeverity())
+	}
+	if got := err.WithSeverity(SeverityWarning).GetSeverity(); got != SeverityWarning {
+		t.Fatalf("expected overridden severity, got %s", got)
+	}
+}
+
+func TestSeverityLevel(t *testing.T) {
+	if SeverityDebug.Level() != slog.LevelDebug || SeverityWarning.Level() != slog.LevelWarn ||
+		SeverityError.Level() != slog.LevelError || SeverityCritical.Level() <= slog.LevelError {
+		t.Fatal("unexpected slog level mapping")
+	}
+	for _, s := range []Severity{SeverityDebug, SeverityInfo, SeverityWarning, SeverityError, SeverityCritical} {
+		if parsed, ok := ParseSeverity(s.String()); !ok || parsed != s {
+			t.Fatalf("expected %s to round-trip through ParseSeverity, got %s", s, parsed)
+		}
+	}
+	if _, ok := ParseSeverity("fatal"); ok {
+		t.Fatal("expected unknown severity to be rejected")
+	}
+}
+
+// withResponseLogger logs error responses to a buffer for the duration of the test.
+func withResponseLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := ResponseLogger()
+	SetResponseLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { SetResponseLogger(previous) })
+	return &buf
+}
+
+func TestResponseLogger(t *testing.T) {
+	buf := withResponseLogger(t)
+
+	// The converters don't log
+	notFound := NewStandardError(NOT_FOUND, "user not found").WithMetadata("user_id", "42")
+	notFound.(*StructuredError).ToHTTP(httptest.NewRecorder())
+	notFound.(*StructuredError).ToHTTPJSON()
+	notFound.(*StructuredError).ToGRPCStatus()
+	if buf.Len() != 0 {
+		t.Fatalf("expected the converters not to log, got %q", buf.String())
+	}
+
+	rec := httptest.NewRecorder()
+	WriteError(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil), fmt.Errorf("load user: %w", notFound))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+	interceptor := UnaryServerInterceptor()
+	_, err := interceptor(context.Background(), nil, nil, func(context.Context, any) (any, error) {
+		return nil, NewStandardError(DATA_LOSS, "checksum mismatch")
+	})
+	if status.Code(err) != codes.DataLoss {
+		t.Fatalf("expected a DataLoss status, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two log lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "level=INFO") || !strings.Contains(lines[0], "error.code=NOT_FOUND") ||
+		!strings.Contains(lines[0], "error.metadata.user_id=42") {
+		t.Fatalf("unexpected log line %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "level=ERROR+4") || !strings.Contains(lines[1], "error.severity=critical") {
+		t.Fatalf("unexpected log line %q", lines[1])
+	}
+}
+
+func TestServerInterceptorStatusErrors(t *testing.T) {
+	buf := withResponseLogger(t)
+	interceptor := UnaryServerInterceptor()
+
+	unavailable := status.Error(codes.Unavailable, "try later")
+	_, err := interceptor(context.Background(), nil, nil, func(context.Context, any) (any, error) {
+		return nil, unavailable
+	})
+	if err != unavailable || buf.Len() != 0 {
+		t.Fatalf("expected status errors to be returned unchanged, got %v", err)
+	}
+
+	_, err = interceptor(context.Background(), nil, nil, func(context.Context, any) (any, error) {
+		return nil, sql.ErrNoRows
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected foreign errors to be classified, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package xerr
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackMode controls how much of the call stack is captured when an error is created.
+type StackMode int
+
+const (
+	// StackOff disables stack capture.
+	StackOff StackMode = iota
+	// StackCaller captures only the location (file:line) where the error was created.
+	StackCaller
+	// StackFull captures the call stack up to DefaultStackDepth frames.
+	StackFull
+)
+
+// DefaultStackMode is the stack capture mode used by the constructors and wrapping functions.
+// Stack capture is off by default; set it to StackCaller or StackFull during program
+// initialization to record where errors are created.
+var DefaultStackMode = StackOff
+
+// DefaultStackDepth is the maximum number of frames recorded in StackFull mode.
+var DefaultStackDepth = 32
+
+// pkgPrefix is the function name prefix of this package, used to skip internal frames.
+var pkgPrefix = reflect.TypeOf(StructuredError{}).PkgPath() + "."
+
+// Frame represents a program counter inside a stack frame.
+// For historical reasons, like runtime.Callers, the value is the return address,
+// i.e. one past the instruction of the call.
+type Frame uintptr
+
+// pc returns the program counter of the call instruction.
+func (f Frame) pc() uintptr { return uintptr(f) - 1 }
+
+// location returns the function name, file and line of the frame.
+func (f Frame) location() (function string, file string, line int) {
+	fn := runtime.FuncForPC(f.pc())
+	if fn == nil {
+		return "unknown", "unknown", 0
+	}
+	file, line = fn.FileLine(f.pc())
+	return fn.Name(), file, line
+}
+
+// Function returns the fully qualified name of the function of the frame.
+func (f Frame) Function() string {
+	function, _, _ := f.location()
+	return function
+}
+
+// File returns the full path to the file that contains the function of the frame.
+func (f Frame) File() string {
+	_, file, _ := f.location()
+	return file
+}
+
+// Line returns the line number of the frame.
+func (f Frame) Line() int {
+	_, _, line := f.location()
+	return line
+}
+
+// String returns the frame as file:line.
+func (f Frame) String() string {
+	_, file, line := f.location()
+	return file + ":" + strconv.Itoa(line)
+}
+
+// Format formats the frame according to the fmt.Formatter interface.
+//
+//	%s    source file base name
+//	%d    source line
+//	%n    function name without the package path
+//	%v    equivalent to %s:%d
+//
+// Format accepts the '+' flag for %s and %v:
+//
+//	%+s   function name and full path of the source file, separated by "\n\t"
+//	%+v   equivalent to %+s:%d
+func (f Frame) Format(s fmt.State, verb rune) {
+	function, file, line := f.location()
+	switch verb {
+	case 's':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, function+"\n\t"+file)
+			return
+		}
+		_, _ = io.WriteString(s, path.Base(file))
+	case 'd':
+		_, _ = io.WriteString(s, strconv.Itoa(line))
+	case 'n':
+		_, _ = io.WriteString(s, shortFunctionName(function))
+	case 'v':
+		f.Format(s, 's')
+		_, _ = io.WriteString(s, ":")
+		f.Format(s, 'd')
+	}
+}
+
+// shortFunctionName strips the package path from a fully qualified function name.
+func shortFunctionName(name string) string {
+	name = name[strings.LastIndex(name, "/")+1:]
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// StackTrace is a stack of frames from innermost (newest) to outermost (oldest).
+// Its shape follows the StackTrace() convention used by other error libraries,
+// so error reporters that understand that convention can read it.
+type StackTrace []Frame
+
+// Format formats the stack trace according to the fmt.Formatter interface.
+//
+//	%s    lists source files for each frame in the stack
+//	%v    lists source file and line number for each frame in the stack
+//
+// Format accepts the '+' flag for %v, which prints function, file and line of each frame
+// on separate lines.
+func (st StackTrace) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			for _, f := range st {
+				_, _ = io.WriteString(s, "\n")
+				f.Format(s, verb)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, "[")
+		for i, f := range st {
+			if i > 0 {
+				_, _ = io.WriteString(s, " ")
+			}
+			f.Format(s, verb)
+		}
+		_, _ = io.WriteString(s, "]")
+	}
+}
+
+// captureStack records the call stack according to mode, skipping the frames of this package.
+// It returns nil when mode is StackOff.
+func captureStack(mode StackMode) StackTrace {
+	var depth int
+	switch mode {
+	case StackCaller:
+		depth = 1
+	case StackFull:
+		depth = DefaultStackDepth
+	default:
+		return nil
+	}
+	if depth <= 0 {
+		return nil
+	}
+
+	// Allow room for the frames of this package, which are skipped below.
+	pcs := make([]uintptr, depth+16)
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+
+	skip := 0
+	for skip < len(pcs) && isInternalFrame(Frame(pcs[skip])) {
+		skip++
+	}
+	pcs = pcs[skip:]
+	if len(pcs) > depth {
+		pcs = pcs[:depth]
+	}
+
+	st := make(StackTrace, len(pcs))
+	for i, pc := range pcs {
+		st[i] = Frame(pc)
+	}
+	return st
+}
+
+// isInternalFrame reports whether the frame belongs to this package, excluding its tests.
+func isInternalFrame(f Frame) bool {
+	function, file, _ := f.location()
+	return strings.HasPrefix(function, pkgPrefix) && !strings.HasSuffix(file, "_test.go")
+}
+
+// StackTrace returns the call stack captured when the error was created,
+// or nil if stack capture was disabled.
+func (e *StructuredError) StackTrace() StackTrace {
+	return e.stack
+}
+
+// Origin returns the frame where the error was created.
+// The second return value is false if no stack was captured.
+func (e *StructuredError) Origin() (Frame, bool) {
+	if len(e.stack) == 0 {
+		return 0, false
+	}
+	return e.stack[0], true
+}
+
+// WithStack returns a copy of the error with the full call stack of the caller,
+// regardless of DefaultStackMode.
+func (e *StructuredError) WithStack() Error {
+	c := e.clone()
+	c.stack = captureStack(StackFull)
+	return c
+}
+
+// WithCaller returns a copy of the error that records only the caller's location,
+// regardless of DefaultStackMode.
+func (e *StructuredError) WithCaller() Error {
+	c := e.clone()
+	c.stack = captureStack(StackCaller)
+	return c
+}
+
+// WithoutStack returns a copy of the error without a captured stack.
+func (e *StructuredError) WithoutStack() Error {
+	c := e.clone()
+	c.stack = nil
+	return c
+}
@@ -0,0 +1,109 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func withStackMode(t *testing.T, mode StackMode) {
+	t.Helper()
+	previous := DefaultStackMode
+	DefaultStackMode = mode
+	t.Cleanup(func() { DefaultStackMode = previous })
+}
+
+func TestStackOffByDefault(t *testing.T) {
+	err := New("CODE", "msg").(*StructuredError)
+	if err.StackTrace() != nil {
+		t.Fatalf("expected no stack, got %v", err.StackTrace())
+	}
+	if _, ok := err.Origin(); ok {
+		t.Fatal("expected no origin")
+	}
+}
+
+func TestStackCallerMode(t *testing.T) {
+	withStackMode(t, StackCaller)
+
+	_, _, line, _ := runtime.Caller(0)
+	err := New("CODE", "msg").(*StructuredError)
+
+	origin, ok := err.Origin()
+	if !ok {
+		t.Fatal("expected origin to be captured")
+	}
+	if len(err.StackTrace()) != 1 {
+		t.Fatalf("expected a single frame, got %d", len(err.StackTrace()))
+	}
+	if filepath.Base(origin.File()) != "stack_test.go" || origin.Line() != line+1 {
+		t.Fatalf("expected stack_test.go:%d, got %v", line+1, origin)
+	}
+	if !strings.HasSuffix(origin.Function(), "TestStackCallerMode") {
+		t.Fatalf("unexpected function %s", origin.Function())
+	}
+}
+
+func TestStackFullModeSkipsPackageFrames(t *testing.T) {
+	withStackMode(t, StackFull)
+
+	err := Wrap(errors.New("boom"), INTERNAL).(*StructuredError)
+	st := err.StackTrace()
+	if len(st) < 2 {
+		t.Fatalf("expected several frames, got %d", len(st))
+	}
+	if !strings.HasSuffix(st[0].Function(), "TestStackFullModeSkipsPackageFrames") {
+		t.Fatalf("expected first frame in the test, got %s", st[0].Function())
+	}
+	if got := fmt.Sprintf("%+v", st); !strings.Contains(got, "stack_test.go") {
+		t.Fatalf("expected formatted stack to contain the test file, got %s", got)
+	}
+}
+
+func TestStackDepth(t *testing.T) {
+	withStackMode(t, StackFull)
+	previous := DefaultStackDepth
+	DefaultStackDepth = 2
+	t.Cleanup(func() { DefaultStackDepth = previous })
+
+	err := New("CODE", "msg").(*StructuredError)
+	if len(err.StackTrace()) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(err.StackTrace()))
+	}
+}
+
+func TestPerCallStackCapture(t *testing.T) {
+	err := New("CODE", "msg").(*StructuredError)
+
+	withStack := err.WithStack().(*StructuredError)
+	if len(withStack.StackTrace()) == 0 {
+		t.Fatal("expected WithStack to capture a stack")
+	}
+	if err.StackTrace() != nil {
+		t.Fatal("expected receiver to be unchanged")
+	}
+
+	caller := err.WithCaller().(*StructuredError)
+	if len(caller.StackTrace()) != 1 {
+		t.Fatalf("expected a single frame, got %d", len(caller.StackTrace()))
+	}
+
+	if withStack.WithoutStack().(*StructuredError).StackTrace() != nil {
+		t.Fatal("expected WithoutStack to drop the stack")
+	}
+}
+
+func TestStackTraceConvention(t *testing.T) {
+	type stackTracer interface {
+		StackTrace() StackTrace
+	}
+	withStackMode(t, StackFull)
+
+	var err error = New("CODE", "msg")
+	if _, ok := err.(stackTracer); !ok {
+		t.Fatal("expected StructuredError to implement StackTrace()")
+	}
+}
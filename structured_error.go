@@ -3,19 +3,36 @@ package xerr
 
 import (
 	"fmt"
+	"maps"
+	"time"
+
 	"google.golang.org/grpc/codes"
 )
 
 // StructuredError represents a rich error with code, message, and metadata.
 // It implements the Error interface and can be converted to/from gRPC status and HTTP responses.
 // This is the concrete implementation that is returned by the factory functions.
+//
+// StructuredError has value semantics: every With* modifier returns a fresh error
+// with its own copy of the metadata and reason, and never changes the receiver.
+// This makes package-level sentinel errors safe to share between goroutines.
 type StructuredError struct {
-	reason   Reason            // Reason interface implementation
-	GRPCCode codes.Code        // gRPC status code
-	HTTPCode int               // HTTP status code
-	Metadata map[string]string // Optional context (trace ID, field, etc.)
-	Domain   string            // Domain for gRPC ErrorInfo
-	Cause    error             // Original error that caused this error
+	reason     Reason             // Reason interface implementation
+	GRPCCode   codes.Code         // gRPC status code
+	HTTPCode   int                // HTTP status code
+	Metadata   map[string]string  // Optional context (trace ID, field, etc.), in its string encoding
+	values     map[string]Value   // Typed values of the Metadata entries that are not strings
+	Domain     string             // Domain for gRPC ErrorInfo
+	Cause      error              // Original error that caused this error
+	severity   Severity           // Overrides the severity of the code when set
+	retry      Retryability       // Overrides the classification derived from GRPCCode when set
+	retryDelay time.Duration      // Delay before retrying, for RetryableAfterDelay
+	stack      StackTrace         // Call stack captured at creation, if enabled
+	stackMode  *StackMode         // Overrides DefaultStackMode for errors created from a definition
+	occurrence *occurrence        // Unique ID, assigned lazily, and creation time of this error instance
+	docsURL    string             // Link to the documentation of the error
+	context    string             // Breadcrumb added by WrapMsg, rendered before the cause
+	redaction  []*RedactionPolicy // Metadata redaction policies set with WithRedaction
 }
 
 // Accessor methods for StructuredError
@@ -59,9 +76,55 @@ func (e *StructuredError) GetHTTPCode() int {
 	return e.HTTPCode
 }
 
-// GetMetadata returns the error metadata.
+// GetMetadata returns the error metadata with every value in its string encoding.
+// The parameters of a ParamsReason are included, see GetMetadataValues.
 func (e *StructuredError) GetMetadata() map[string]string {
-	return e.Metadata
+	return stringMetadata(e.allMetadata())
+}
+
+// GetMetadataValue returns the typed metadata value for key.
+// The second return value is false if the key is not set.
+func (e *StructuredError) GetMetadataValue(key string) (Value, bool) {
+	if s, ok := e.Metadata[key]; ok {
+		return typedValue(s, e.values[key]), true
+	}
+	if pr, ok := e.reason.(ParamsReason); ok {
+		v, ok := pr.Params()[key]
+		return v, ok
+	}
+	return Value{}, false
+}
+
+// GetMetadataValues returns a copy of the typed error metadata.
+// If the reason is a ParamsReason, such as a TemplateReason, its parameters are included;
+// metadata set on the error takes precedence over parameters with the same key.
+func (e *StructuredError) GetMetadataValues() map[string]Value {
+	return e.allMetadata()
+}
+
+// allMetadata returns the parameters of the reason merged with the metadata of the error.
+func (e *StructuredError) allMetadata() map[string]Value {
+	pr, ok := e.reason.(ParamsReason)
+	if !ok {
+		return typedMetadata(e.Metadata, e.values)
+	}
+	metadata := pr.Params()
+	if len(metadata) == 0 {
+		return typedMetadata(e.Metadata, e.values)
+	}
+	for k, s := range e.Metadata {
+		metadata[k] = typedValue(s, e.values[k])
+	}
+	return metadata
+}
+
+// LocalizedReason returns the user-facing reason for the locale.
+// If the reason does not implement Localizer, the default user-facing reason is returned.
+func (e *StructuredError) LocalizedReason(locale string) string {
+	if l, ok := e.reason.(Localizer); ok {
+		return l.Localize(locale)
+	}
+	return e.GetUserReason()
 }
 
 // GetCause returns the underlying cause of the error.
@@ -70,7 +133,11 @@ func (e *StructuredError) GetCause() error {
 }
 
 // Error implements the error interface.
+// Errors annotated with WrapMsg render their breadcrumb path, as in "load user: [NOT_FOUND] row not found".
 func (e *StructuredError) Error() string {
+	if e.context != "" && e.Cause != nil {
+		return e.context + ": " + e.Cause.Error()
+	}
 	if e.reason == nil {
 		return "unknown error"
 	}
@@ -78,55 +145,177 @@ func (e *StructuredError) Error() string {
 }
 
 // New creates a new Error with the given code and message.
+// The HTTP and gRPC status codes are resolved through the standard code catalog;
+// codes that are not in the catalog default to HTTP 500 and codes.Unknown.
+// If message is empty, the default message of the catalog code is used.
+// The options are applied in order and can override any attribute, see Option.
 // It returns an Error interface that can be used with all the methods defined in the interface.
-func New(code string, message string) Error {
-	return NewWithHTTPAndGRPC(code, message, 500, codes.Unknown)
+func New(code string, message string, opts ...Option) Error {
+	return NewStandardError(Code(code), message, opts...)
 }
 
-// WithReason adds a user-facing reason to the error.
-func (e *StructuredError) WithReason(reason string) Error {
-	if defaultReason, ok := e.reason.(*DefaultReason); ok {
-		defaultReason.WithReason(reason)
-	} else {
-		// If the reason is not a DefaultReason, create a new one with the same code and message
-		newReason := NewDefaultReason(e.GetCode(), e.GetMessage()).WithReason(reason)
-		e.reason = newReason
+// NewStandardError creates a new Error from a code in the standard code catalog.
+// The HTTP and gRPC status codes are taken from the catalog entry, and an empty
+// message is replaced with the default message of the code.
+// The options are applied in order and can override any attribute, see Option.
+func NewStandardError(code Code, message string, opts ...Option) Error {
+	if message == "" {
+		message = code.DefaultMessage()
+	}
+	e := &StructuredError{
+		reason:     NewDefaultReason(string(code), message),
+		GRPCCode:   code.GRPCCode(),
+		HTTPCode:   code.HTTPCode(),
+		stack:      captureStack(DefaultStackMode),
+		occurrence: newOccurrence(),
 	}
+	e.apply(opts)
 	return e
 }
 
-// WithCustomReason sets a custom implementation of the Reason interface.
+// clone returns a shallow copy of the error with its own copy of the metadata and a fresh occurrence.
+// The reason is shared; Reason implementations provided by this package are never mutated in place.
+func (e *StructuredError) clone() *StructuredError {
+	c := *e
+	c.Metadata = copyStringMetadata(e.Metadata)
+	c.values = copyMetadata(e.values)
+	c.occurrence = newOccurrence()
+	return &c
+}
+
+// apply applies the options to the error in place.
+// It must only be called on an error that is not shared yet.
+func (e *StructuredError) apply(opts []Option) {
+	for _, opt := range opts {
+		opt(e)
+	}
+}
+
+// with returns a copy of the error with the options applied.
+func (e *StructuredError) with(opts ...Option) *StructuredError {
+	c := e.clone()
+	c.apply(opts)
+	return c
+}
+
+// setMetadata sets a metadata entry in place, allocating the maps if needed.
+// It must only be called on an error that is not shared yet.
+func (e *StructuredError) setMetadata(key string, value Value) {
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]string)
+	}
+	e.Metadata[key] = value.String()
+	if value.kind == KindString {
+		delete(e.values, key)
+		return
+	}
+	if e.values == nil {
+		e.values = make(map[string]Value)
+	}
+	e.values[key] = value
+}
+
+// replaceMetadata replaces the metadata of the error in place.
+// It must only be called on an error that is not shared yet.
+func (e *StructuredError) replaceMetadata(metadata map[string]Value) {
+	e.Metadata, e.values = nil, nil
+	for k, v := range metadata {
+		e.setMetadata(k, v)
+	}
+}
+
+// typedMetadata returns the typed metadata made of the string encoded entries of metadata
+// and their typed values, or nil if it is empty. See typedValue.
+func typedMetadata(metadata map[string]string, values map[string]Value) map[string]Value {
+	if len(metadata) == 0 {
+		return nil
+	}
+	m := make(map[string]Value, len(metadata))
+	for k, s := range metadata {
+		m[k] = typedValue(s, values[k])
+	}
+	return m
+}
+
+// typedValue returns the typed value of a metadata entry encoded as s.
+// A typed value whose encoding differs from s is stale, because the string map was
+// changed directly, and the entry is then a string.
+func typedValue(s string, value Value) Value {
+	if value.kind != KindString && value.String() == s {
+		return value
+	}
+	return StringValue(s)
+}
+
+// copyStringMetadata returns a copy of the string metadata map, or nil if it is empty.
+func copyStringMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	return maps.Clone(metadata)
+}
+
+// copyMetadata returns a copy of the metadata map, or nil if it is empty.
+// Values are immutable, so they are shared between the copies.
+func copyMetadata(metadata map[string]Value) map[string]Value {
+	if len(metadata) == 0 {
+		return nil
+	}
+	c := make(map[string]Value, len(metadata))
+	for k, v := range metadata {
+		c[k] = v
+	}
+	return c
+}
+
+// WithReason returns a copy of the error with a user-facing reason.
+// If the reason is a TemplateReason, the user-facing reason is used as its template.
+func (e *StructuredError) WithReason(reason string) Error {
+	return e.with(WithUserReason(reason))
+}
+
+// WithCustomReason returns a copy of the error with a custom implementation of the Reason interface.
 // This allows for more flexible error reason handling.
 func (e *StructuredError) WithCustomReason(reason Reason) Error {
-	e.reason = reason
-	return e
+	return e.with(WithCustomReason(reason))
 }
 
-// WithGRPCCode sets the gRPC status code.
+// WithGRPCCode returns a copy of the error with the given gRPC status code.
 func (e *StructuredError) WithGRPCCode(code codes.Code) Error {
-	e.GRPCCode = code
-	return e
+	c := e.clone()
+	c.GRPCCode = code
+	return c
 }
 
-// WithHTTPCode sets the HTTP status code.
+// WithHTTPCode returns a copy of the error with the given HTTP status code.
 func (e *StructuredError) WithHTTPCode(code int) Error {
-	e.HTTPCode = code
-	return e
+	c := e.clone()
+	c.HTTPCode = code
+	return c
 }
 
-// WithMetadata adds metadata to the error.
+// WithMetadata returns a copy of the error with the given string metadata entry added.
 func (e *StructuredError) WithMetadata(key string, value string) Error {
-	if e.Metadata == nil {
-		e.Metadata = make(map[string]string)
-	}
-	e.Metadata[key] = value
-	return e
+	return e.WithMetadataValue(key, StringValue(value))
+}
+
+// WithMetadataValue returns a copy of the error with the given typed metadata entry added.
+// The value is converted with AnyValue, so numbers, booleans, times, slices and maps keep their type.
+func (e *StructuredError) WithMetadataValue(key string, value any) Error {
+	return e.with(WithMetadata(key, value))
 }
 
 // Is implements the errors.Is interface for error comparison.
+// A StructuredError or Definition target matches errors with the same code, while a Code
+// target matches errors with that code or any of its descendants.
 func (e *StructuredError) Is(target error) bool {
-	if se, ok := target.(*StructuredError); ok {
-		return e.GetCode() == se.GetCode()
+	switch t := target.(type) {
+	case *StructuredError:
+		return e.GetCode() == t.GetCode()
+	case *Definition:
+		return e.GetCode() == t.template.GetCode()
+	case Code:
+		return Code(e.GetCode()).Within(t)
 	}
 	return false
 }
@@ -140,8 +329,10 @@ func (e *StructuredError) Unwrap() error {
 // It returns an Error interface that can be used with all the methods defined in the interface.
 func NewWithHTTPAndGRPC(code string, message string, httpCode int, grpcCode codes.Code) Error {
 	return &StructuredError{
-		reason:   NewDefaultReason(code, message),
-		GRPCCode: grpcCode,
-		HTTPCode: httpCode,
+		reason:     NewDefaultReason(code, message),
+		GRPCCode:   grpcCode,
+		HTTPCode:   httpCode,
+		stack:      captureStack(DefaultStackMode),
+		occurrence: newOccurrence(),
 	}
 }
@@ -0,0 +1,95 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestModifiersDoNotMutateReceiver(t *testing.T) {
+	sentinel := New("USER_NOT_FOUND", "user not found").(*StructuredError)
+
+	modified := sentinel.
+		WithMetadata("user_id", "42").
+		WithReason("We could not find your account").
+		WithHTTPCode(404)
+
+	if len(sentinel.Metadata) != 0 {
+		t.Fatalf("expected sentinel metadata to be empty, got %v", sentinel.Metadata)
+	}
+	if sentinel.GetUserReason() != "" {
+		t.Fatalf("expected sentinel user reason to be empty, got %q", sentinel.GetUserReason())
+	}
+	if sentinel.GetHTTPCode() != 500 {
+		t.Fatalf("expected sentinel HTTP code 500, got %d", sentinel.GetHTTPCode())
+	}
+	if modified.GetMetadata()["user_id"] != "42" || modified.GetHTTPCode() != 404 {
+		t.Fatalf("expected modifications on the copy, got %v / %d", modified.GetMetadata(), modified.GetHTTPCode())
+	}
+	if !errors.Is(modified, sentinel) {
+		t.Fatal("expected copy to match sentinel with errors.Is")
+	}
+}
+
+func TestModifiersCopyMetadata(t *testing.T) {
+	base := New("CODE", "msg").WithMetadata("a", "1")
+	first := base.WithMetadata("b", "2")
+	second := base.WithMetadata("b", "3")
+
+	if _, ok := base.GetMetadata()["b"]; ok {
+		t.Fatalf("expected base to be unchanged, got %v", base.GetMetadata())
+	}
+	if first.GetMetadata()["b"] != "2" || second.GetMetadata()["b"] != "3" {
+		t.Fatalf("expected independent copies, got %v and %v", first.GetMetadata(), second.GetMetadata())
+	}
+
+	base.GetMetadata()["a"] = "changed"
+	if base.GetMetadata()["a"] != "1" {
+		t.Fatal("expected GetMetadata to return a copy")
+	}
+}
+
+func TestWrapWithReasonDoesNotMutateChain(t *testing.T) {
+	inner := New("INNER", "inner failure")
+	outer := fmt.Errorf("context: %w", inner)
+
+	wrapped := WrapWithReason(outer, NewDefaultReason("OUTER", "outer failure"))
+	if wrapped.GetCode() != "OUTER" {
+		t.Fatalf("expected code OUTER, got %s", wrapped.GetCode())
+	}
+	if inner.GetCode() != "INNER" {
+		t.Fatalf("expected inner error to keep code INNER, got %s", inner.GetCode())
+	}
+}
+
+func TestSentinelConcurrentModifiers(t *testing.T) {
+	sentinel := New("USER_NOT_FOUND", "user not found").(*StructuredError)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprint(i)
+			err := sentinel.
+				WithMetadata("user_id", id).
+				WithReason("reason "+id).(*StructuredError).
+				WithErrorInfo("users", map[string]string{"request": id}).(*StructuredError).
+				WithBadRequest(map[string]string{"id": id})
+			if got := err.GetMetadata()["user_id"]; got != id {
+				t.Errorf("expected user_id %s, got %s", id, got)
+			}
+			if got := err.GetUserReason(); got != "reason "+id {
+				t.Errorf("expected reason %q, got %q", "reason "+id, got)
+			}
+			_ = sentinel.GetErrorInfo()
+			_, _ = sentinel.ToHTTPJSON()
+		}(i)
+	}
+	wg.Wait()
+
+	if len(sentinel.Metadata) != 0 || sentinel.GetUserReason() != "" || sentinel.Domain != "" {
+		t.Fatalf("expected sentinel to be unchanged, got %+v", sentinel)
+	}
+}
@@ -0,0 +1,569 @@
+package xerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Kind is the kind of a metadata Value.
+type Kind int
+
+const (
+	// KindString is a string value.
+	KindString Kind = iota
+	// KindInt is a signed integer value.
+	KindInt
+	// KindFloat is a floating-point value.
+	KindFloat
+	// KindBool is a boolean value.
+	KindBool
+	// KindTime is a timestamp value.
+	KindTime
+	// KindList is a list of values.
+	KindList
+	// KindMap is a map of string keys to values.
+	KindMap
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "bool"
+	case KindTime:
+		return "time"
+	case KindList:
+		return "list"
+	case KindMap:
+		return "map"
+	default:
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Value is a typed metadata value.
+// It can hold a string, an integer, a float, a boolean, a timestamp,
+// a list of values or a map of values. The zero Value is an empty string.
+// Values are immutable once created.
+//
+// Over HTTP and gRPC, values are sent in native JSON and google.protobuf.Value types, which
+// have no timestamp kind and a single number kind. Two kinds are therefore not restored:
+// a KindTime value is received as a KindString holding an RFC 3339 timestamp, and a KindFloat
+// value holding an integral number, such as 2.0, as a KindInt. The Time and Float64 accessors
+// convert them back. Over gRPC, integers beyond ±2^53 are also received as a KindString
+// holding their exact decimal encoding, which the Int64 accessor converts back.
+type Value struct {
+	kind Kind
+	str  string
+	num  int64
+	flt  float64
+	t    time.Time
+	list []Value
+	m    map[string]Value
+}
+
+// StringValue returns a Value for a string.
+func StringValue(s string) Value {
+	return Value{kind: KindString, str: s}
+}
+
+// IntValue returns a Value for an int.
+func IntValue(n int) Value {
+	return Int64Value(int64(n))
+}
+
+// Int64Value returns a Value for an int64.
+func Int64Value(n int64) Value {
+	return Value{kind: KindInt, num: n}
+}
+
+// FloatValue returns a Value for a float64.
+func FloatValue(f float64) Value {
+	return Value{kind: KindFloat, flt: f}
+}
+
+// BoolValue returns a Value for a bool.
+func BoolValue(b bool) Value {
+	v := Value{kind: KindBool}
+	if b {
+		v.num = 1
+	}
+	return v
+}
+
+// TimeValue returns a Value for a time.Time.
+func TimeValue(t time.Time) Value {
+	return Value{kind: KindTime, t: t}
+}
+
+// ListValue returns a Value for a list of values.
+func ListValue(values ...Value) Value {
+	return Value{kind: KindList, list: append([]Value(nil), values...)}
+}
+
+// MapValue returns a Value for a map of values.
+func MapValue(values map[string]Value) Value {
+	m := make(map[string]Value, len(values))
+	for k, v := range values {
+		m[k] = v
+	}
+	return Value{kind: KindMap, m: m}
+}
+
+// AnyValue returns a Value for an arbitrary Go value.
+// Strings, integers, floats, booleans, times, slices and maps with string keys are
+// converted to the matching kind; a Value is returned unchanged; durations, errors,
+// fmt.Stringers and any other value are stored as their string representation.
+func AnyValue(v any) Value {
+	switch x := v.(type) {
+	case Value:
+		return x
+	case nil:
+		return StringValue("")
+	case string:
+		return StringValue(x)
+	case int:
+		return Int64Value(int64(x))
+	case int8:
+		return Int64Value(int64(x))
+	case int16:
+		return Int64Value(int64(x))
+	case int32:
+		return Int64Value(int64(x))
+	case int64:
+		return Int64Value(x)
+	case uint:
+		return uintValue(uint64(x))
+	case uint8:
+		return Int64Value(int64(x))
+	case uint16:
+		return Int64Value(int64(x))
+	case uint32:
+		return Int64Value(int64(x))
+	case uint64:
+		return uintValue(x)
+	case float32:
+		return FloatValue(float64(x))
+	case float64:
+		return FloatValue(x)
+	case bool:
+		return BoolValue(x)
+	case time.Time:
+		return TimeValue(x)
+	case time.Duration:
+		return StringValue(x.String())
+	case []Value:
+		return ListValue(x...)
+	case []string:
+		list := make([]Value, len(x))
+		for i, s := range x {
+			list[i] = StringValue(s)
+		}
+		return Value{kind: KindList, list: list}
+	case []any:
+		list := make([]Value, len(x))
+		for i, e := range x {
+			list[i] = AnyValue(e)
+		}
+		return Value{kind: KindList, list: list}
+	case map[string]Value:
+		return MapValue(x)
+	case map[string]string:
+		m := make(map[string]Value, len(x))
+		for k, s := range x {
+			m[k] = StringValue(s)
+		}
+		return Value{kind: KindMap, m: m}
+	case map[string]any:
+		m := make(map[string]Value, len(x))
+		for k, e := range x {
+			m[k] = AnyValue(e)
+		}
+		return Value{kind: KindMap, m: m}
+	case error:
+		return StringValue(x.Error())
+	case fmt.Stringer:
+		return StringValue(x.String())
+	default:
+		return StringValue(fmt.Sprint(x))
+	}
+}
+
+// uintValue returns an integer Value, or a float Value if n overflows an int64.
+func uintValue(n uint64) Value {
+	if n > math.MaxInt64 {
+		return FloatValue(float64(n))
+	}
+	return Int64Value(int64(n))
+}
+
+// Kind returns the kind of the value.
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+// Any returns the value as a Go value: string, int64, float64, bool, time.Time,
+// []any or map[string]any.
+func (v Value) Any() any {
+	switch v.kind {
+	case KindInt:
+		return v.num
+	case KindFloat:
+		return v.flt
+	case KindBool:
+		return v.num == 1
+	case KindTime:
+		return v.t
+	case KindList:
+		list := make([]any, len(v.list))
+		for i, e := range v.list {
+			list[i] = e.Any()
+		}
+		return list
+	case KindMap:
+		m := make(map[string]any, len(v.m))
+		for k, e := range v.m {
+			m[k] = e.Any()
+		}
+		return m
+	default:
+		return v.str
+	}
+}
+
+// String returns the string encoding of the value.
+// Strings are returned as is, numbers and booleans in their Go syntax,
+// times in RFC 3339 format and lists and maps as JSON.
+func (v Value) String() string {
+	switch v.kind {
+	case KindString:
+		return v.str
+	case KindInt:
+		return strconv.FormatInt(v.num, 10)
+	case KindFloat:
+		return strconv.FormatFloat(v.flt, 'g', -1, 64)
+	case KindBool:
+		return strconv.FormatBool(v.num == 1)
+	case KindTime:
+		return v.t.Format(time.RFC3339Nano)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// Int64 returns the value as an int64.
+// Integral floats and strings holding an integer are converted.
+// The second return value is false if the value is not an integer.
+func (v Value) Int64() (int64, bool) {
+	switch v.kind {
+	case KindInt:
+		return v.num, true
+	case KindFloat:
+		if v.flt == math.Trunc(v.flt) && math.Abs(v.flt) <= maxExactInt {
+			return int64(v.flt), true
+		}
+	case KindString:
+		if n, err := strconv.ParseInt(v.str, 10, 64); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// Float64 returns the value as a float64.
+// Integers and strings holding a number are converted.
+// The second return value is false if the value is not a number.
+func (v Value) Float64() (float64, bool) {
+	switch v.kind {
+	case KindFloat:
+		return v.flt, true
+	case KindInt:
+		return float64(v.num), true
+	case KindString:
+		if f, err := strconv.ParseFloat(v.str, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// Bool returns the value as a bool.
+// Strings holding a boolean are converted.
+// The second return value is false if the value is not a boolean.
+func (v Value) Bool() (bool, bool) {
+	switch v.kind {
+	case KindBool:
+		return v.num == 1, true
+	case KindString:
+		if b, err := strconv.ParseBool(v.str); err == nil {
+			return b, true
+		}
+	}
+	return false, false
+}
+
+// Time returns the value as a time.Time.
+// Strings in RFC 3339 format are converted, since timestamps travel as strings on the wire.
+// The second return value is false if the value is not a timestamp.
+func (v Value) Time() (time.Time, bool) {
+	switch v.kind {
+	case KindTime:
+		return v.t, true
+	case KindString:
+		if t, err := time.Parse(time.RFC3339Nano, v.str); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// List returns the elements of a list value.
+// The second return value is false if the value is not a list.
+func (v Value) List() ([]Value, bool) {
+	if v.kind != KindList {
+		return nil, false
+	}
+	return append([]Value(nil), v.list...), true
+}
+
+// Map returns the entries of a map value.
+// The second return value is false if the value is not a map.
+func (v Value) Map() (map[string]Value, bool) {
+	if v.kind != KindMap {
+		return nil, false
+	}
+	m := make(map[string]Value, len(v.m))
+	for k, e := range v.m {
+		m[k] = e
+	}
+	return m, true
+}
+
+// Equal reports whether v and w hold the same kind and value.
+func (v Value) Equal(w Value) bool {
+	if v.kind != w.kind {
+		return false
+	}
+	switch v.kind {
+	case KindTime:
+		return v.t.Equal(w.t)
+	case KindList:
+		if len(v.list) != len(w.list) {
+			return false
+		}
+		for i := range v.list {
+			if !v.list[i].Equal(w.list[i]) {
+				return false
+			}
+		}
+		return true
+	case KindMap:
+		if len(v.m) != len(w.m) {
+			return false
+		}
+		for k, e := range v.m {
+			if other, ok := w.m[k]; !ok || !e.Equal(other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return v.str == w.str && v.num == w.num && v.flt == w.flt
+	}
+}
+
+// MarshalJSON encodes the value as its native JSON type.
+// Times are encoded as RFC 3339 strings, and decode as KindString.
+// NaN and infinite floats, which JSON cannot represent, are encoded as the strings
+// "NaN", "+Inf" and "-Inf", and also decode as KindString.
+func (v Value) MarshalJSON() ([]byte, error) {
+	switch v.kind {
+	case KindInt:
+		return []byte(strconv.FormatInt(v.num, 10)), nil
+	case KindFloat:
+		if math.IsNaN(v.flt) || math.IsInf(v.flt, 0) {
+			return json.Marshal(v.String())
+		}
+		return json.Marshal(v.flt)
+	case KindBool:
+		return []byte(strconv.FormatBool(v.num == 1)), nil
+	case KindTime:
+		return json.Marshal(v.t.Format(time.RFC3339Nano))
+	case KindList:
+		if v.list == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(v.list)
+	case KindMap:
+		if v.m == nil {
+			return []byte("{}"), nil
+		}
+		return json.Marshal(v.m)
+	default:
+		return json.Marshal(v.str)
+	}
+}
+
+// UnmarshalJSON decodes a JSON value.
+// Integral numbers decode as KindInt, other numbers as KindFloat, and null as an empty string.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	*v = fromJSON(raw)
+	return nil
+}
+
+// fromJSON converts a value decoded with json.Decoder.UseNumber to a Value.
+func fromJSON(raw any) Value {
+	switch x := raw.(type) {
+	case json.Number:
+		if n, err := x.Int64(); err == nil {
+			return Int64Value(n)
+		}
+		f, _ := x.Float64()
+		return FloatValue(f)
+	case []any:
+		list := make([]Value, len(x))
+		for i, e := range x {
+			list[i] = fromJSON(e)
+		}
+		return Value{kind: KindList, list: list}
+	case map[string]any:
+		m := make(map[string]Value, len(x))
+		for k, e := range x {
+			m[k] = fromJSON(e)
+		}
+		return Value{kind: KindMap, m: m}
+	default:
+		return AnyValue(x)
+	}
+}
+
+// maxExactInt is the largest integer up to which every integer is exactly representable
+// as a float64.
+const maxExactInt = 1 << 53
+
+// toProto converts the value to a google.protobuf.Value.
+// Times are encoded as RFC 3339 strings, and convert back as KindString.
+// Integers beyond ±2^53, which a double cannot hold exactly, are encoded as strings too.
+func (v Value) toProto() *structpb.Value {
+	switch v.kind {
+	case KindInt:
+		if v.num > maxExactInt || v.num < -maxExactInt {
+			return structpb.NewStringValue(v.String())
+		}
+		return structpb.NewNumberValue(float64(v.num))
+	case KindFloat:
+		return structpb.NewNumberValue(v.flt)
+	case KindBool:
+		return structpb.NewBoolValue(v.num == 1)
+	case KindTime:
+		return structpb.NewStringValue(v.t.Format(time.RFC3339Nano))
+	case KindList:
+		list := &structpb.ListValue{Values: make([]*structpb.Value, len(v.list))}
+		for i, e := range v.list {
+			list.Values[i] = e.toProto()
+		}
+		return structpb.NewListValue(list)
+	case KindMap:
+		return structpb.NewStructValue(metadataToStruct(v.m))
+	default:
+		return structpb.NewStringValue(v.str)
+	}
+}
+
+// valueFromProto converts a google.protobuf.Value to a Value.
+// Integral numbers convert to KindInt and null to an empty string.
+func valueFromProto(pv *structpb.Value) Value {
+	switch k := pv.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		f := k.NumberValue
+		if f == math.Trunc(f) && math.Abs(f) <= maxExactInt {
+			return Int64Value(int64(f))
+		}
+		return FloatValue(f)
+	case *structpb.Value_BoolValue:
+		return BoolValue(k.BoolValue)
+	case *structpb.Value_StringValue:
+		return StringValue(k.StringValue)
+	case *structpb.Value_ListValue:
+		values := k.ListValue.GetValues()
+		list := make([]Value, len(values))
+		for i, e := range values {
+			list[i] = valueFromProto(e)
+		}
+		return Value{kind: KindList, list: list}
+	case *structpb.Value_StructValue:
+		return Value{kind: KindMap, m: metadataFromStruct(k.StructValue)}
+	default:
+		return StringValue("")
+	}
+}
+
+// metadataToStruct converts typed metadata to a google.protobuf.Struct.
+func metadataToStruct(metadata map[string]Value) *structpb.Struct {
+	s := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(metadata))}
+	for k, v := range metadata {
+		s.Fields[k] = v.toProto()
+	}
+	return s
+}
+
+// metadataFromStruct converts a google.protobuf.Struct to typed metadata.
+func metadataFromStruct(s *structpb.Struct) map[string]Value {
+	metadata := make(map[string]Value, len(s.GetFields()))
+	for k, pv := range s.GetFields() {
+		metadata[k] = valueFromProto(pv)
+	}
+	return metadata
+}
+
+// stringMetadata returns the string encoding of typed metadata, or nil if it is empty.
+func stringMetadata(metadata map[string]Value) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		m[k] = v.String()
+	}
+	return m
+}
+
+// hasTypedMetadata reports whether any metadata value is not a string.
+func hasTypedMetadata(metadata map[string]Value) bool {
+	for _, v := range metadata {
+		if v.kind != KindString {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of the metadata in sorted order.
+func sortedKeys(metadata map[string]Value) []string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,249 @@
+package xerr
+
+import (
+	"encoding/json"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestValueGetters(t *testing.T) {
+	ts := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	err := New("CODE", "msg").
+		WithMetadata("name", "alice").
+		WithMetadataValue("attempts", 3).
+		WithMetadataValue("ratio", 0.5).
+		WithMetadataValue("admin", true).
+		WithMetadataValue("at", ts).
+		WithMetadataValue("tags", []string{"a", "b"}).
+		WithMetadataValue("limits", map[string]any{"max": 10})
+
+	if v, _ := err.GetMetadataValue("attempts"); v.Kind() != KindInt {
+		t.Fatalf("expected int kind, got %s", v.Kind())
+	}
+	if n, ok := mustValue(t, err, "attempts").Int64(); !ok || n != 3 {
+		t.Fatalf("expected 3, got %d", n)
+	}
+	if f, ok := mustValue(t, err, "ratio").Float64(); !ok || f != 0.5 {
+		t.Fatalf("expected 0.5, got %v", f)
+	}
+	if b, ok := mustValue(t, err, "admin").Bool(); !ok || !b {
+		t.Fatal("expected true")
+	}
+	if got, ok := mustValue(t, err, "at").Time(); !ok || !got.Equal(ts) {
+		t.Fatalf("expected %v, got %v", ts, got)
+	}
+	if list, ok := mustValue(t, err, "tags").List(); !ok || len(list) != 2 || list[1].String() != "b" {
+		t.Fatalf("unexpected list %v", list)
+	}
+	limits, ok := mustValue(t, err, "limits").Map()
+	if n, _ := limits["max"].Int64(); !ok || n != 10 {
+		t.Fatalf("unexpected map %v", limits)
+	}
+	if _, ok := mustValue(t, err, "name").Int64(); ok {
+		t.Fatal("expected string value not to convert to int")
+	}
+
+	metadata := err.GetMetadata()
+	if metadata["name"] != "alice" || metadata["attempts"] != "3" || metadata["admin"] != "true" ||
+		metadata["at"] != "2025-07-01T12:00:00Z" || metadata["tags"] != `["a","b"]` {
+		t.Fatalf("unexpected string metadata %v", metadata)
+	}
+}
+
+func mustValue(t *testing.T, err Error, key string) Value {
+	t.Helper()
+	v, ok := err.GetMetadataValue(key)
+	if !ok {
+		t.Fatalf("expected metadata %q", key)
+	}
+	return v
+}
+
+func TestTypedMetadataHTTPJSON(t *testing.T) {
+	err := New("CODE", "msg").
+		WithMetadata("name", "alice").
+		WithMetadataValue("attempts", 3).
+		WithMetadataValue("admin", true).
+		WithMetadataValue("tags", []any{"a", 1}).(*StructuredError)
+
+	body, status := err.ToHTTPJSON()
+
+	var raw map[string]any
+	if e := json.Unmarshal(body, &raw); e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+	metadata := raw["metadata"].(map[string]any)
+	if metadata["attempts"] != float64(3) || metadata["admin"] != true || metadata["name"] != "alice" {
+		t.Fatalf("expected native JSON types, got %v", metadata)
+	}
+
+	decoded, e := FromHTTPJSON(body, status)
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+	for k, want := range err.GetMetadataValues() {
+		if got, _ := decoded.GetMetadataValue(k); !got.Equal(want) {
+			t.Errorf("%s: expected %v (%s), got %v (%s)", k, want, want.Kind(), got, got.Kind())
+		}
+	}
+}
+
+func TestTypedMetadataGRPCStatus(t *testing.T) {
+	err := New("CODE", "msg").
+		WithMetadata("name", "alice").
+		WithMetadataValue("attempts", 3).
+		WithMetadataValue("ratio", 0.25).
+		WithMetadataValue("limits", map[string]any{"max": 10, "soft": false}).(*StructuredError)
+
+	st := err.ToGRPCStatus()
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			if info.Metadata["attempts"] != "3" || info.Metadata["name"] != "alice" {
+				t.Fatalf("expected string-encoded ErrorInfo metadata, got %v", info.Metadata)
+			}
+		}
+	}
+
+	decoded := FromGRPCStatus(st)
+	for k, want := range err.GetMetadataValues() {
+		if got, _ := decoded.GetMetadataValue(k); !got.Equal(want) {
+			t.Errorf("%s: expected %v (%s), got %v (%s)", k, want, want.Kind(), got, got.Kind())
+		}
+	}
+}
+
+func TestStringMetadataUnchanged(t *testing.T) {
+	withOccurrence(t, "01TEST", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	withExposurePolicy(t, nil, false)
+	err := New("CODE", "msg").WithMetadata("k", "v").(*StructuredError)
+	body, _ := err.ToHTTPJSON()
+	if string(body) != `{"code":"CODE","message":"msg","metadata":{"k":"v"},"id":"01TEST","timestamp":"2026-01-02T03:04:05Z"}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+	if len(err.ToGRPCStatus().Details()) != 1 {
+		t.Fatal("expected only ErrorInfo for string metadata")
+	}
+}
+
+func TestTypedMetadataLossyKinds(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := New("CODE", "msg").
+		WithMetadataValue("at", at).
+		WithMetadataValue("ratio", 2.0).(*StructuredError)
+
+	body, status := err.ToHTTPJSON()
+	fromHTTP, _ := FromHTTPJSON(body, status)
+	fromGRPC := FromGRPCStatus(err.ToGRPCStatus())
+	for name, decoded := range map[string]Error{"http": fromHTTP, "grpc": fromGRPC} {
+		v := mustValue(t, decoded, "at")
+		if got, ok := v.Time(); v.Kind() != KindString || !ok || !got.Equal(at) {
+			t.Errorf("%s: expected a timestamp string, got %v (%s)", name, v, v.Kind())
+		}
+		v = mustValue(t, decoded, "ratio")
+		if got, ok := v.Float64(); v.Kind() != KindInt || !ok || got != 2 {
+			t.Errorf("%s: expected an integer, got %v (%s)", name, v, v.Kind())
+		}
+	}
+}
+
+func TestStringMetadataField(t *testing.T) {
+	err := New("CODE", "msg").WithMetadataValue("attempts", 3).(*StructuredError)
+	if err.Metadata["attempts"] != "3" {
+		t.Fatalf("expected the string encoding in Metadata, got %v", err.Metadata)
+	}
+
+	// Entries changed through the string map are strings
+	err.Metadata["attempts"] = "many"
+	err.Metadata["name"] = "alice"
+	if v := mustValue(t, err, "attempts"); v.Kind() != KindString || v.String() != "many" {
+		t.Fatalf("expected the changed entry to be a string, got %v (%s)", v, v.Kind())
+	}
+	if v := mustValue(t, err, "name"); v.String() != "alice" {
+		t.Fatalf("expected the added entry, got %v", v)
+	}
+
+	var httpErr HTTPError
+	body, _ := New("CODE", "msg").WithMetadataValue("attempts", 3).(*StructuredError).ToHTTPJSON()
+	if e := json.Unmarshal(body, &httpErr); e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+	if httpErr.Metadata["attempts"] != "3" || httpErr.MetadataValues["attempts"].Kind() != KindInt {
+		t.Fatalf("unexpected decoded metadata %v %v", httpErr.Metadata, httpErr.MetadataValues)
+	}
+}
+
+func TestNonFiniteFloatMetadataHTTP(t *testing.T) {
+	err := New("CODE", "msg").
+		WithMetadataValue("nan", math.NaN()).
+		WithMetadataValue("inf", math.Inf(1)).
+		WithMetadataValue("neg", math.Inf(-1)).(*StructuredError)
+
+	body, status := err.ToHTTPJSON()
+	var raw map[string]any
+	if e := json.Unmarshal(body, &raw); e != nil {
+		t.Fatalf("expected a JSON body, got %q: %v", body, e)
+	}
+	metadata := raw["metadata"].(map[string]any)
+	if metadata["nan"] != "NaN" || metadata["inf"] != "+Inf" || metadata["neg"] != "-Inf" {
+		t.Fatalf("expected non-finite floats as strings, got %v", metadata)
+	}
+
+	decoded, e := FromHTTPJSON(body, status)
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+	if f, ok := mustValue(t, decoded, "nan").Float64(); !ok || !math.IsNaN(f) {
+		t.Errorf("expected NaN, got %v", f)
+	}
+	if f, ok := mustValue(t, decoded, "neg").Float64(); !ok || !math.IsInf(f, -1) {
+		t.Errorf("expected -Inf, got %v", f)
+	}
+
+	rec := httptest.NewRecorder()
+	err.ToHTTP(rec)
+	if e := json.Unmarshal(rec.Body.Bytes(), &raw); e != nil {
+		t.Fatalf("expected ToHTTP to write a JSON body, got %q: %v", rec.Body.String(), e)
+	}
+}
+
+func TestLargeIntMetadataGRPCStatus(t *testing.T) {
+	const big = int64(1234567890123456789)
+	err := New("CODE", "msg").
+		WithMetadataValue("id", big).
+		WithMetadataValue("ids", []any{big}).(*StructuredError)
+
+	decoded := FromGRPCStatus(err.ToGRPCStatus())
+	if got, ok := mustValue(t, decoded, "id").Int64(); !ok || got != big {
+		t.Fatalf("expected %d, got %v", big, mustValue(t, decoded, "id"))
+	}
+	if got := decoded.GetMetadata()["id"]; got != "1234567890123456789" {
+		t.Fatalf("expected the exact string encoding, got %q", got)
+	}
+	list, _ := mustValue(t, decoded, "ids").List()
+	if got, ok := list[0].Int64(); len(list) != 1 || !ok || got != big {
+		t.Fatalf("expected [%d], got %v", big, list)
+	}
+
+	// A sender that encodes the integer as a double loses precision, so the ErrorInfo string is kept
+	pb := err.ToGRPCStatus().Proto()
+	for _, detail := range pb.GetDetails() {
+		var s structpb.Struct
+		if detail.UnmarshalTo(&s) == nil {
+			s.Fields["id"] = structpb.NewNumberValue(float64(big))
+			if e := detail.MarshalFrom(&s); e != nil {
+				t.Fatalf("unexpected error: %v", e)
+			}
+		}
+	}
+	decoded = FromGRPCStatus(status.FromProto(pb))
+	if got, ok := mustValue(t, decoded, "id").Int64(); !ok || got != big {
+		t.Fatalf("expected the exact ErrorInfo value %d, got %v", big, mustValue(t, decoded, "id"))
+	}
+}
@@ -1,37 +1,140 @@
 package xerr
 
-import (
-	"errors"
-	"google.golang.org/grpc/codes"
-)
+import "fmt"
+
+// Wrap wraps an existing error with a structured error using a code from the standard code catalog.
+// The HTTP and gRPC status codes are derived from the code, and the message is the wrapped error's message.
+// The options are applied in order and can override any attribute, see Option.
+// When code is UNKNOWN or empty, the code is inferred with the DefaultClassifier, see Classifier.
+// The original error stays reachable through Unwrap and GetCause.
+func Wrap(err error, code Code, opts ...Option) Error {
+	if err == nil {
+		return nil
+	}
+	e := wrapCode(err, DefaultClassifier.resolve(err, code), err.Error())
+	e.apply(opts)
+	return e
+}
+
+// Wrapf wraps an existing error with a structured error using a code from the standard code catalog
+// and a formatted message.
+// Options may follow the format arguments; they are applied as for Wrap instead of being formatted:
+//
+//	xerr.Wrapf(err, xerr.INTERNAL, "load user %d", id, xerr.WithMetadata("user_id", id))
+//
+// When code is UNKNOWN or empty, the code is inferred with the DefaultClassifier, see Classifier.
+// The original error stays reachable through Unwrap and GetCause.
+func Wrapf(err error, code Code, format string, args ...any) Error {
+	if err == nil {
+		return nil
+	}
+	args, opts := splitOptions(args)
+	e := wrapCode(err, DefaultClassifier.resolve(err, code), fmt.Sprintf(format, args...))
+	e.apply(opts)
+	return e
+}
+
+// wrapCode creates a structured error for code with err as its cause.
+func wrapCode(err error, code Code, message string) *StructuredError {
+	return &StructuredError{
+		reason:     NewDefaultReason(string(code), message),
+		GRPCCode:   code.GRPCCode(),
+		HTTPCode:   code.HTTPCode(),
+		Cause:      err,
+		stack:      captureStack(DefaultStackMode),
+		occurrence: newOccurrence(),
+	}
+}
 
 // WrapWithReason wraps an existing error with a structured error using the provided Reason.
 // It returns an Error interface that can be used with all the methods defined in the interface.
-func WrapWithReason(err error, reason Reason) Error {
+//
+// The new error is an outer layer with err as its cause: an Error in the chain of err keeps
+// its own code and is never modified. The outer layer inherits the attributes of the nearest
+// Error in the chain under the DefaultMergePolicy, and the options override them.
+func WrapWithReason(err error, reason Reason, opts ...Option) Error {
+	return WrapWithPolicy(err, reason, DefaultMergePolicy, opts...)
+}
+
+// WrapWithPolicy wraps an existing error as WrapWithReason does, inheriting the attributes
+// selected by policy from the nearest Error in the chain of err.
+// The status codes that are not inherited are derived from the code of the reason.
+// It returns nil if err is nil.
+func WrapWithPolicy(err error, reason Reason, policy MergePolicy, opts ...Option) Error {
 	if err == nil {
 		return nil
 	}
 
-	// If it's already a StructuredError, just update the reason
-	var se *StructuredError
-	if errors.As(err, &se) {
-		se.reason = reason
-		return se
+	var code Code
+	if reason != nil {
+		code = Code(reason.Code())
 	}
-	return &StructuredError{
-		reason:   reason,
-		GRPCCode: codes.Unknown,
-		HTTPCode: 500,
-		Cause:    err,
+	e := &StructuredError{
+		reason:     reason,
+		GRPCCode:   code.GRPCCode(),
+		HTTPCode:   code.HTTPCode(),
+		Cause:      err,
+		stack:      captureStack(DefaultStackMode),
+		occurrence: newOccurrence(),
 	}
+	if inner, ok := First(err); ok {
+		policy.inherit(e, inner)
+	}
+	e.apply(opts)
+	return e
 }
 
-// WrapDefault wraps an existing error with a structured error using the default UNKNOWN code.
+// WrapMsg annotates err with a breadcrumb that describes the operation that failed, as
+// fmt.Errorf("load user: %w", err) does, without changing its code: the new layer inherits
+// every attribute of the nearest Error in the chain of err, which is created with WrapDefault
+// if there is none. Error renders the breadcrumb path, such as "load user: [NOT_FOUND] row not found",
+// while the message sent over HTTP and gRPC follows the DefaultMessagePolicy.
+// It returns nil if err is nil.
+func WrapMsg(err error, message string) Error {
+	if err == nil {
+		return nil
+	}
+	inner, ok := First(err)
+	if !ok {
+		inner = WrapDefault(err)
+		err = inner
+	}
+	e := WrapWithPolicy(err, inner.GetReason(), InheritAll).(*StructuredError)
+	e.context = message
+	return e
+}
+
+// WrapMsgf annotates err with a formatted breadcrumb, as WrapMsg does.
+// It returns nil if err is nil.
+func WrapMsgf(err error, format string, args ...any) Error {
+	return WrapMsg(err, fmt.Sprintf(format, args...))
+}
+
+// WrapDefault wraps an existing error with a structured error using the code inferred by the
+// DefaultClassifier, such as CANCELLED for context.Canceled or NOT_FOUND for sql.ErrNoRows,
+// and the default UNKNOWN code for errors no rule matches.
+// Errors that aggregate several errors, such as the result of errors.Join, are converted to a MultiError.
+// An Error is returned unchanged, and an error wrapping one, such as fmt.Errorf("load: %w", err),
+// is wrapped with the code of the nearest Error in its chain, as WrapWithReason does.
 // It returns an Error interface that can be used with all the methods defined in the interface.
 func WrapDefault(err error) Error {
 	if err == nil {
 		return nil
 	}
+	if xe, ok := err.(Error); ok {
+		return xe
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		if m := Join(joined.Unwrap()...); m != nil {
+			return m
+		}
+	}
+	if inner, ok := First(err); ok {
+		return WrapWithReason(err, inner.GetReason())
+	}
+	if code := DefaultClassifier.resolve(err, UNKNOWN); code != UNKNOWN {
+		return wrapCode(err, code, err.Error())
+	}
 	reason := NewDefaultReason("UNKNOWN", err.Error())
 	return WrapWithReason(err, reason)
 }
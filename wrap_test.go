@@ -0,0 +1,140 @@
+package xerr
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestWrapDerivesStatusFromCode(t *testing.T) {
+	original := errors.New("database connection failed")
+	err := Wrap(original, UNAVAILABLE)
+
+	if err.GetCode() != "UNAVAILABLE" {
+		t.Fatalf("expected code UNAVAILABLE, got %s", err.GetCode())
+	}
+	if err.GetHTTPCode() != http.StatusServiceUnavailable || err.GetGRPCCode() != codes.Unavailable {
+		t.Fatalf("expected 503/Unavailable, got %d/%s", err.GetHTTPCode(), err.GetGRPCCode())
+	}
+	if err.GetMessage() != original.Error() {
+		t.Fatalf("expected message %q, got %q", original.Error(), err.GetMessage())
+	}
+	if errors.Unwrap(err) != original || err.GetCause() != original {
+		t.Fatal("expected original error to be reachable through Unwrap and GetCause")
+	}
+}
+
+func TestWrapf(t *testing.T) {
+	original := errors.New("no rows")
+	err := Wrapf(original, NOT_FOUND, "user %d not found", 42)
+
+	if err.GetMessage() != "user 42 not found" {
+		t.Fatalf("unexpected message %q", err.GetMessage())
+	}
+	if err.GetHTTPCode() != http.StatusNotFound {
+		t.Fatalf("expected HTTP 404, got %d", err.GetHTTPCode())
+	}
+	if !errors.Is(err, original) {
+		t.Fatal("expected errors.Is to find the original error")
+	}
+}
+
+func TestWrapfOptions(t *testing.T) {
+	err := Wrapf(errors.New("no rows"), NOT_FOUND, "user %d not found", 42,
+		WithSeverity(SeverityWarning), WithMetadata("user_id", 42))
+
+	if err.GetMessage() != "user 42 not found" {
+		t.Fatalf("expected the options to be left out of the message, got %q", err.GetMessage())
+	}
+	if err.GetSeverity() != SeverityWarning {
+		t.Fatalf("expected the severity option to be applied, got %s", err.GetSeverity())
+	}
+	if v, _ := err.GetMetadataValue("user_id"); v.Kind() != KindInt {
+		t.Fatalf("expected the metadata option to be applied, got %v", err.GetMetadataValues())
+	}
+}
+
+func TestWrapNil(t *testing.T) {
+	if Wrap(nil, INTERNAL) != nil || Wrapf(nil, INTERNAL, "x") != nil {
+		t.Fatal("expected nil when wrapping nil")
+	}
+}
+
+func TestWrapWithReasonCreatesLayer(t *testing.T) {
+	inner := Wrap(errors.New("connection refused"), UNAVAILABLE,
+		WithDomain("db.example.com"),
+		WithMetadata("host", "db-1"),
+		WithRetryDelay(2*time.Second),
+	)
+	outer := WrapWithReason(inner, NewDefaultReason("ORDER.CHECKOUT_FAILED", "checkout failed"),
+		WithMetadata("order_id", 42),
+	)
+
+	if outer.GetCode() != "ORDER.CHECKOUT_FAILED" || inner.GetCode() != string(UNAVAILABLE) {
+		t.Fatalf("expected both codes to be kept, got %s and %s", outer.GetCode(), inner.GetCode())
+	}
+	if outer.GetCause() != inner {
+		t.Fatal("expected the inner error to be the cause of the outer layer")
+	}
+	if got := Codes(outer); !slices.Equal(got, []Code{"ORDER.CHECKOUT_FAILED", UNAVAILABLE}) {
+		t.Fatalf("expected the outer and inner codes in the chain, got %v", got)
+	}
+
+	// The outer layer inherits the attributes of the inner one
+	if outer.GetHTTPCode() != http.StatusServiceUnavailable || outer.GetGRPCCode() != codes.Unavailable {
+		t.Fatalf("expected the inherited status, got %d/%s", outer.GetHTTPCode(), outer.GetGRPCCode())
+	}
+	if md := outer.GetMetadata(); md["host"] != "db-1" || md["order_id"] != "42" {
+		t.Fatalf("expected inherited and own metadata, got %v", md)
+	}
+	if outer.(*StructuredError).Domain != "db.example.com" || outer.GetRetryDelay() != 2*time.Second {
+		t.Fatal("expected the inherited domain and retry delay")
+	}
+	if _, ok := inner.GetMetadataValue("order_id"); ok {
+		t.Fatal("expected the inner error to be unchanged")
+	}
+}
+
+func TestWrapWithPolicy(t *testing.T) {
+	inner := New(string(NOT_FOUND), "row not found", WithMetadata("table", "users"), WithSeverity(SeverityCritical))
+	reason := NewDefaultReason(string(INTERNAL), "lookup failed")
+
+	tests := []struct {
+		name         string
+		policy       MergePolicy
+		opts         []Option
+		wantHTTP     int
+		wantMetadata bool
+		wantSeverity Severity
+	}{
+		{"none", InheritNone, nil, http.StatusInternalServerError, false, SeverityError},
+		{"status", InheritStatus, nil, http.StatusNotFound, false, SeverityError},
+		{"metadata and severity", InheritMetadata | InheritSeverity, nil, http.StatusInternalServerError, true, SeverityCritical},
+		{"all overridden", InheritAll, []Option{WithHTTPStatus(http.StatusBadGateway)}, http.StatusBadGateway, true, SeverityCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WrapWithPolicy(inner, reason, tt.policy, tt.opts...)
+			if err.GetHTTPCode() != tt.wantHTTP {
+				t.Errorf("expected HTTP %d, got %d", tt.wantHTTP, err.GetHTTPCode())
+			}
+			if _, ok := err.GetMetadataValue("table"); ok != tt.wantMetadata {
+				t.Errorf("expected inherited metadata %t, got %v", tt.wantMetadata, err.GetMetadata())
+			}
+			if err.GetSeverity() != tt.wantSeverity {
+				t.Errorf("expected severity %s, got %s", tt.wantSeverity, err.GetSeverity())
+			}
+		})
+	}
+
+	if WrapWithPolicy(nil, reason, InheritAll) != nil {
+		t.Fatal("expected nil when wrapping nil")
+	}
+	if !InheritAll.Has(InheritStatus|InheritRetry) || InheritStatus.Has(InheritStatus|InheritMetadata) {
+		t.Fatal("expected Has to require every flag")
+	}
+}